@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+var auditCmd = &cli.Command{
+	Name:        "audit",
+	Description: "queries the audit journal of the layer mutations",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "layer",
+			Usage: "only mutations of the layer.",
+		},
+		&cli.StringFlag{
+			Name:  "region",
+			Usage: "only mutations of the target region.",
+		},
+		&cli.StringFlag{
+			Name:  "action",
+			Usage: "only mutations of the action: publish or delete.",
+		},
+		&cli.DurationFlag{
+			Name:  "since",
+			Usage: "only mutations recorded in the duration, e.g. 24h.",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "write the entries as JSON lines.",
+		},
+	},
+	Action: func(cc *cli.Context) error {
+		filter := internal.AuditFilter{
+			Layer:  cc.String("layer"),
+			Region: cc.String("region"),
+			Action: cc.String("action"),
+		}
+
+		if action := filter.Action; action != "" && action != layer.ActionPublish && action != layer.ActionDelete {
+			return usageErrorf(`invalid action %q, expected one of: publish, delete`, action)
+		}
+
+		if since := cc.Duration("since"); since > 0 {
+			filter.Since = time.Now().Add(-since)
+		}
+
+		entries, err := internal.NewJournal(cc.Path("audit-log")).Entries(filter)
+		if err != nil {
+			return err
+		}
+
+		if !cc.Bool("json") {
+			return internal.WriteAudit(cc.App.Writer, entries)
+		}
+
+		enc := json.NewEncoder(cc.App.Writer)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+// auditOption returns the option recording the layer mutations of the
+// command into the audit journal, failing when it is not writable.
+func auditOption(cc *cli.Context, identity *internal.Identity) (layer.Option, error) {
+	journal := internal.NewJournal(cc.Path("audit-log"))
+	if err := journal.Check(); err != nil {
+		return nil, err
+	}
+
+	return layer.WithRecorder(journal.Recorder(cc.Command.Name, identity)), nil
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+)
+
+// identityClientKey is the app metadata key holding the client resolving the
+// caller identity, e.g. a fake client for testing.
+const identityClientKey = "identity-client"
+
+// awsFlags are the global flags defining the aws credentials.
+var awsFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "profile",
+		Usage:   "aws shared config profile.",
+		EnvVars: []string{"AWS_PROFILE"},
+	},
+	&cli.StringFlag{
+		Name:    "role-arn",
+		Usage:   "arn of the role assumed for all aws requests.",
+		EnvVars: []string{"LB_ROLE_ARN"},
+	},
+	&cli.StringFlag{
+		Name:    "external-id",
+		Usage:   "external id of the assumed role.",
+		EnvVars: []string{"LB_EXTERNAL_ID"},
+	},
+	&cli.StringFlag{
+		Name:    "role-session-name",
+		Usage:   "session name of the assumed role.",
+		EnvVars: []string{"LB_ROLE_SESSION_NAME"},
+	},
+	&cli.StringFlag{
+		Name:    "mfa-serial",
+		Usage:   "serial number or arn of the MFA device of the assumed role, the token code is prompted.",
+		EnvVars: []string{"LB_MFA_SERIAL"},
+	},
+}
+
+// loadAWSConfig loads the aws config with the credentials defined by the
+// flags, logging the API calls of every client and resolving the caller identity.
+func loadAWSConfig(cc *cli.Context, logger *slog.Logger) (aws.Config, *internal.Identity, error) {
+	for _, name := range []string{"external-id", "role-session-name", "mfa-serial"} {
+		if cc.String(name) != "" && cc.String("role-arn") == "" {
+			return aws.Config{}, nil, usageErrorf(`flag %q requires "role-arn"`, name)
+		}
+	}
+
+	region := stsRegion(cc)
+
+	cfg, err := internal.LoadAWSConfig(cc.Context, internal.Credentials{
+		Profile:         cc.String("profile"),
+		RoleArn:         cc.String("role-arn"),
+		ExternalID:      cc.String("external-id"),
+		RoleSessionName: cc.String("role-session-name"),
+		MFASerial:       cc.String("mfa-serial"),
+		STSRegion:       region,
+	})
+	if err != nil {
+		return aws.Config{}, nil, err
+	}
+
+	cfg.APIOptions = append(cfg.APIOptions, internal.LogAPICalls(logger))
+
+	svc, ok := cc.App.Metadata[identityClientKey].(internal.IdentityClient)
+	if !ok {
+		svc = internal.NewSTSClient(cfg, region)
+	}
+
+	identity, err := callerIdentity(cc.Context, svc, logger)
+	if err != nil {
+		return aws.Config{}, nil, err
+	}
+
+	return cfg, identity, nil
+}
+
+// callerIdentity resolves the caller identity, failing only for invalid
+// credentials, as the sts endpoint may be unreachable, e.g. when the lambda
+// endpoint is an emulator, in which case the identity is empty.
+func callerIdentity(ctx context.Context, svc internal.IdentityClient, logger *slog.Logger) (*internal.Identity, error) {
+	identity, err := internal.CallerIdentity(ctx, svc)
+	if internal.IsAuthError(err) {
+		return nil, err
+	}
+
+	if err != nil {
+		logger.WarnContext(ctx, "unable to resolve the caller identity", "error", err)
+		return &internal.Identity{}, nil
+	}
+
+	return identity, nil
+}
+
+// stsRegion returns the first region of the flags, used by the sts requests
+// when the aws config has no region, or empty to use the default one.
+func stsRegion(cc *cli.Context) string {
+	conf, err := loadConfig(cc)
+	if err != nil {
+		return ""
+	}
+
+	regions, err := internal.ExpandRegions(cc.StringSlice("regions"), conf.RegionGroups)
+	if err != nil || len(regions) == 0 {
+		return ""
+	}
+
+	return regions[0]
+}
+
+// printIdentity shows the caller identity when resolved, keeping the output of
+// the command clean.
+func printIdentity(cc *cli.Context, identity *internal.Identity) {
+	if identity.Arn == "" {
+		return
+	}
+
+	fmt.Fprintf(cc.App.ErrWriter, "Using identity: %s\n", identity)
+}
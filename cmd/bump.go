@@ -6,134 +6,240 @@
 package cmd
 
 import (
-	"bytes"
+	"context"
 	"errors"
-	"fmt"
+	"log/slog"
+	"slices"
 	"strings"
 
-	"golang.org/x/sync/errgroup"
-
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/pterm/pterm"
 	"github.com/urfave/cli/v2"
 
 	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
 )
 
 var bumpCmd = &cli.Command{
 	Name:        "bump",
 	Description: "bump layer to latest version across regions",
-	Flags: []cli.Flag{
-		&cli.StringSliceFlag{
-			Name:     "regions",
-			Aliases:  []string{"r"},
-			Usage:    "list of regions separated by comma.",
-			Required: true,
+	Flags: slices.Concat(regionFlags, []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "atomic",
+			Usage: "deletes the versions published by the bump in every region when any region fails.",
 		},
-	},
-	ArgsUsage: "layer-name",
+		&cli.BoolFlag{
+			Name:  "no-hooks",
+			Usage: "skips the hooks defined in the config file.",
+		},
+		&cli.BoolFlag{
+			Name:  "no-smoke-test",
+			Usage: "skips the smoke test of the layer defined in the config file.",
+		},
+		&cli.BoolFlag{
+			Name:    "interactive",
+			Aliases: []string{"i"},
+			Usage:   "picks the layer and the regions not set interactively.",
+		},
+		&cli.BoolFlag{
+			Name:    "yes",
+			Aliases: []string{"y"},
+			Usage:   "skips the confirmation of the plan in a terminal.",
+		},
+		&cli.StringFlag{
+			Name:  "rollout",
+			Usage: "bumps the regions in the waves of the rollout defined in the config file.",
+		},
+	}),
+	ArgsUsage: "[layer-name]",
 	Action: func(cc *cli.Context) error {
+		p := newPrompter(cc)
+
+		var picker prompter
+		if cc.Bool("interactive") {
+			if p == nil {
+				return usageErrorf(`flag "interactive" requires a terminal`)
+			}
+
+			picker = p
+		}
+
 		name := cc.Args().First()
-		if name == "" {
-			return errors.New(`required argument "layer-name" not set`)
+		if name == "" && picker == nil {
+			return usageErrorf(`required argument "layer-name" not set`)
 		}
 
-		regions := cc.StringSlice("regions")
-		if len(regions) <= 1 {
-			return errors.New(`required flag "regions" must contain at least two regions`)
+		prog, err := newProgress(cc)
+		if err != nil {
+			return err
 		}
 
-		cfg, err := config.LoadDefaultConfig(cc.Context)
+		logger, err := newLogger(cc, internal.LogText)
 		if err != nil {
-			return fmt.Errorf("failed to load aws config: %w", err)
+			return err
 		}
 
-		pterm.Printf(
-			"Bumping layer across regions: %s\n",
-			pterm.Green(strings.Join(regions, ", ")),
-		)
+		notifier, err := newNotifier(cc, logger)
+		if err != nil {
+			return err
+		}
+
+		opts, err := layerOptions(cc)
+		if err != nil {
+			return err
+		}
+
+		cfg, identity, err := loadAWSConfig(cc, logger)
+		if err != nil {
+			return err
+		}
 
-		l := internal.LoadLayer(cfg, name)
+		printIdentity(cc, identity)
 
-		spin, err := spinner(cc.App.Writer, "getting latest version...").Start()
+		regions, err := selectRegions(cc, cfg, picker)
 		if err != nil {
 			return err
 		}
 
-		greatest, err := l.GreatestVersion(cc.Context, regions)
+		if name == "" {
+			if name, err = selectLayer(cc, cfg, prog, picker, regions, opts); err != nil {
+				return err
+			}
+		}
+
+		rollout, waves, err := loadRollout(cc, regions)
 		if err != nil {
-			_ = spin.Stop()
 			return err
 		}
 
-		pterm.Printf(
-			"Greatest version %d in region %s\n",
-			greatest.Number,
-			greatest.Region,
+		prog.Printf(
+			"Bumping layer across regions: %s",
+			pterm.Green(strings.Join(regions, ", ")),
 		)
 
-		if greatest.Number == 0 {
-			_ = spin.Stop()
-			return errors.New("there are no published versions")
+		if rollout != nil {
+			prog.Printf("Rollout waves: %s", waveList(waves))
 		}
 
-		_ = spin.Stop()
+		smoke, err := smokeTestOptions(cc, cfg, name)
+		if err != nil {
+			return err
+		}
+
+		hooks, err := hookOptions(cc, logger)
+		if err != nil {
+			return err
+		}
 
-		multi, err := pterm.DefaultMultiPrinter.Start()
+		audit, err := auditOption(cc, identity)
 		if err != nil {
 			return err
 		}
 
-		g, ctx := errgroup.WithContext(cc.Context)
+		b := layer.NewBalancer(cfg, name, slices.Concat(
+			opts,
+			smoke,
+			hooks,
+			[]layer.Option{layer.WithProgress(prog.Update), audit},
+		)...)
 
-		for _, region := range regions {
-			w := multi.NewWriter()
-			g.Go(func() error {
-				spin, err := spinner(w, fmt.Sprintf("%s: starting...", region)).Start()
-				if err != nil {
-					return err
-				}
+		stop := prog.Status("getting latest version...")
+		plan, err := b.Plan(cc.Context, regions)
 
-				latest, err := l.LatestVersion(ctx, region)
-				if err != nil {
-					return err
-				}
+		stop()
+
+		if err != nil {
+			notifier.send(cc.Context, internal.NewNotification(internal.EventBumpFailed, name, nil, err))
+			return err
+		}
+
+		prog.Printf(
+			"Greatest version %d in region %s",
+			plan.Source.Number,
+			plan.Source.Region,
+		)
+
+		behind := plan.Behind()
+
+		if p != nil && !cc.Bool("yes") && len(behind) > 0 {
+			if err := confirmPlan(prog, p, plan); err != nil {
+				return err
+			}
+		}
+
+		if err := prog.Start(regions); err != nil {
+			return err
+		}
 
-				for i := latest.Number; i < greatest.Number; i++ {
-					current, err := l.FetchVersion(ctx, i+1, greatest.Region)
-					if err != nil {
-						return err
-					}
+		bump := b.Bump
+		if cc.Bool("atomic") {
+			bump = b.BumpAtomic
+		}
 
-					spin.UpdateText(fmt.Sprintf("%s: downloading version %d", region, current.Number))
+		if rollout != nil {
+			r := &layer.Rollout{
+				Waves:       waves,
+				HealthCheck: healthCheck(cc, rollout.HealthCheck, name, plan.Source.Number),
+				Atomic:      cc.Bool("atomic"),
+			}
 
-					buf := &bytes.Buffer{}
-					if err := l.DownloadVersion(ctx, current, buf); err != nil {
-						return err
-					}
+			bump = func(ctx context.Context, plan *layer.Plan) error {
+				return b.Rollout(ctx, plan, r)
+			}
+		}
 
-					current.Region = region
-					current.Content.File = buf.Bytes()
+		if err := bump(cc.Context, plan); err != nil {
+			_ = prog.Stop()
 
-					spin.UpdateText(fmt.Sprintf("%s: publishing version %d", region, current.Number))
+			var rollback *layer.RollbackError
+			if errors.As(err, &rollback) {
+				prog.Printf("Rolled back versions: %s", layer.VersionList(rollback.RolledBack))
 
-					if err := l.PublishVersion(ctx, current); err != nil {
-						return err
-					}
+				if len(rollback.Remaining) > 0 {
+					prog.Printf("Failed to roll back versions: %s", layer.VersionList(rollback.Remaining))
 				}
+			}
 
-				_ = spin.Stop()
-				pterm.Fprint(w, pterm.Sprintf("%s: bump complete", region))
+			n := internal.NewNotification(internal.EventBumpFailed, name, plan.Versions, err)
 
-				return nil
-			})
+			var partial *layer.PartialError
+			if errors.As(err, &partial) {
+				n.Changed = partial.Changed
+			}
+
+			notifier.send(cc.Context, n)
+			return err
 		}
 
-		if err := g.Wait(); err != nil {
+		if err := prog.Stop(); err != nil {
 			return err
 		}
 
-		_, err = multi.Stop()
-		return err
+		if len(behind) > 0 {
+			bumpCompleted(cc, notifier, b, plan, logger)
+		}
+
+		return nil
 	},
 }
+
+// bumpCompleted notifies the regions changed by the bump with the versions
+// published, as skipped versions leave the regions behind the source number.
+func bumpCompleted(cc *cli.Context, notifier *notifier, b *layer.Balancer, plan *layer.Plan, logger *slog.Logger) {
+	versions, err := b.Layer().LatestVersions(cc.Context, plan.Regions())
+	if err != nil {
+		logger.WarnContext(cc.Context, "unable to retrieve the bumped versions", "error", err)
+		return
+	}
+
+	n := internal.NewNotification(internal.EventBumpCompleted, b.Layer().Name, versions, nil)
+
+	for i, v := range versions {
+		if v.Number != plan.Versions[i].Number {
+			n.Changed = append(n.Changed, v.Region)
+		}
+	}
+
+	if len(n.Changed) > 0 {
+		notifier.send(cc.Context, n)
+	}
+}
@@ -0,0 +1,1151 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+	"github.com/faabiosr/lb/layer/layertest"
+)
+
+// mockIdentity resolves a fixed caller identity.
+type mockIdentity struct {
+	err error
+}
+
+func (m *mockIdentity) GetCallerIdentity(context.Context, *sts.GetCallerIdentityInput, ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &sts.GetCallerIdentityOutput{
+		Account: aws.String(layertest.AccountID),
+		Arn:     aws.String("arn:aws:iam::" + layertest.AccountID + ":user/lb"),
+	}, nil
+}
+
+// publishLimit is a fake failing the publishes after the limit is reached.
+type publishLimit struct {
+	*layertest.Fake
+	limit atomic.Int32
+}
+
+func (p *publishLimit) PublishLayerVersion(ctx context.Context, in *lambda.PublishLayerVersionInput, opts ...func(*lambda.Options)) (*lambda.PublishLayerVersionOutput, error) {
+	if p.limit.Add(-1) < 0 {
+		return nil, errors.New("failure")
+	}
+
+	return p.Fake.PublishLayerVersion(ctx, in, opts...)
+}
+
+// mockPrompter answers the prompts with the scripted choices.
+type mockPrompter struct {
+	choice   string
+	confirm  bool
+	options  []string
+	prompted []string
+}
+
+func (m *mockPrompter) Select(text string, options []string) (string, error) {
+	m.prompted = append(m.prompted, text)
+	m.options = options
+
+	return m.choice, nil
+}
+
+func (m *mockPrompter) MultiSelect(text string, options []string) ([]string, error) {
+	m.prompted = append(m.prompted, text)
+	return options, nil
+}
+
+func (m *mockPrompter) Confirm(text string) (bool, error) {
+	m.prompted = append(m.prompted, text)
+	return m.confirm, nil
+}
+
+// testApp creates the app with the layer options and a fixed caller identity.
+func testApp(t *testing.T, opts []layer.Option) *cli.App {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	app := newCmd()
+	app.Writer = io.Discard
+	app.ErrWriter = io.Discard
+	app.Metadata = map[string]any{
+		layerOptionsKey:   opts,
+		identityClientKey: &mockIdentity{},
+	}
+
+	return app
+}
+
+// run runs the app with the arguments, using the fake as lambda service.
+func run(t *testing.T, f *layertest.Fake, args ...string) error {
+	t.Helper()
+
+	return testApp(t, f.Options()).RunContext(context.Background(), append([]string{"lb"}, args...))
+}
+
+func TestVerifyAndBump(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v2")})
+	f.Publish("eu-west-1", "my-layer", layertest.Version{Content: []byte("v1")})
+
+	regions := "us-east-1,eu-west-1,sa-east-1"
+
+	err := run(t, f, "verify", "--regions", regions, "my-layer")
+	if code := exitCode(err); code != exitDrift {
+		t.Errorf("expected exit code '%d', got '%d' (%v)", exitDrift, code, err)
+	}
+
+	if err := run(t, f, "bump", "--regions", regions, "my-layer"); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	for _, region := range []string{"eu-west-1", "sa-east-1"} {
+		if latest := f.Latest(region, "my-layer"); latest != 2 {
+			t.Errorf("expected version '2' in %s, got '%d'", region, latest)
+		}
+	}
+
+	if err := run(t, f, "verify", "--regions", regions, "my-layer"); err != nil {
+		t.Errorf("expected nil, got error %v", err)
+	}
+}
+
+func TestBumpFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(f *layertest.Fake)
+		client func(f *layertest.Fake) layer.Client
+		args   []string
+		code   int
+	}{
+		{
+			name: "missing layer name",
+			args: []string{"bump", "--regions", "us-east-1,eu-west-1"},
+			code: exitUsage,
+		},
+		{
+			name: "unknown region",
+			args: []string{"bump", "--regions", "us-east-1,eu-wst-1", "my-layer"},
+			code: exitUsage,
+		},
+		{
+			name: "unknown flag",
+			args: []string{"bump", "--regionz", "us-east-1,eu-west-1", "my-layer"},
+			code: exitUsage,
+		},
+		{
+			name: "unknown global flag",
+			args: []string{"--unknown", "bump", "--regions", "us-east-1,eu-west-1", "my-layer"},
+			code: exitUsage,
+		},
+		{
+			name: "unknown log format",
+			args: []string{"--log-format", "xml", "bump", "--regions", "us-east-1,eu-west-1", "my-layer"},
+			code: exitUsage,
+		},
+		{
+			name: "no versions",
+			args: []string{"bump", "--regions", "us-east-1,eu-west-1", "my-layer"},
+			code: exitNoVersions,
+		},
+		{
+			name: "failure",
+			setup: func(f *layertest.Fake) {
+				f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+				f.Fail(layertest.OpPublishLayerVersion, "eu-west-1", errors.New("failure"), 0)
+			},
+			args: []string{"bump", "--regions", "us-east-1,eu-west-1", "my-layer"},
+			code: exitFailure,
+		},
+		{
+			name: "partial failure",
+			setup: func(f *layertest.Fake) {
+				f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+				f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v2")})
+			},
+			client: func(f *layertest.Fake) layer.Client {
+				client := &publishLimit{Fake: f}
+				client.limit.Store(1)
+
+				return client
+			},
+			args: []string{"bump", "--regions", "us-east-1,eu-west-1", "my-layer"},
+			code: exitPartial,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := layertest.New()
+			defer f.Close()
+
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			opts := f.Options()
+			if tt.client != nil {
+				opts = append(opts, layer.WithClient(tt.client(f)))
+			}
+
+			err := testApp(t, opts).RunContext(context.Background(), append([]string{"lb"}, tt.args...))
+			if code := exitCode(err); code != tt.code {
+				t.Errorf("expected exit code '%d', got '%d' (%v)", tt.code, code, err)
+			}
+		})
+	}
+}
+
+func TestCredentialFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity *mockIdentity
+		args     []string
+		code     int
+	}{
+		{
+			name:     "external id without role",
+			identity: &mockIdentity{},
+			args:     []string{"--external-id", "id", "verify", "--regions", "us-east-1,eu-west-1", "my-layer"},
+			code:     exitUsage,
+		},
+		{
+			name:     "mfa serial without role",
+			identity: &mockIdentity{},
+			args:     []string{"--mfa-serial", "arn:aws:iam::123456789012:mfa/lb", "verify", "--regions", "us-east-1,eu-west-1", "my-layer"},
+			code:     exitUsage,
+		},
+		{
+			name:     "invalid credentials",
+			identity: &mockIdentity{err: &smithy.GenericAPIError{Code: "InvalidClientTokenId"}},
+			args:     []string{"verify", "--regions", "us-east-1,eu-west-1", "my-layer"},
+			code:     exitAuth,
+		},
+		{
+			name:     "unreachable identity",
+			identity: &mockIdentity{err: &net.DNSError{Err: "no such host", Name: "sts.amazonaws.com", IsNotFound: true}},
+			args:     []string{"layers", "--regions", "us-east-1,eu-west-1"},
+			code:     exitOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := layertest.New()
+			defer f.Close()
+
+			app := testApp(t, f.Options())
+			app.Metadata[identityClientKey] = tt.identity
+
+			err := app.RunContext(context.Background(), append([]string{"lb"}, tt.args...))
+			if code := exitCode(err); code != tt.code {
+				t.Errorf("expected exit code '%d', got '%d' (%v)", tt.code, code, err)
+			}
+		})
+	}
+}
+
+func TestReplicate(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v2")})
+
+	role := "arn:aws:iam::111111111111:role/lb"
+
+	err := run(t, f, "replicate", "--target", role+"=eu-west-1", "--target", role+"=sa-east-1", "my-layer")
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	for _, region := range []string{"eu-west-1", "sa-east-1"} {
+		if latest := f.Latest(region, "my-layer"); latest != 2 {
+			t.Errorf("expected version '2' in %s, got '%d'", region, latest)
+		}
+	}
+
+	err = run(t, f, "replicate", "--target", "arn:aws:iam::111111111111:user/lb=eu-west-1", "my-layer")
+	if code := exitCode(err); code != exitUsage {
+		t.Errorf("expected exit code '%d', got '%d' (%v)", exitUsage, code, err)
+	}
+}
+
+func TestMirror(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	vendor := "arn:aws:lambda:us-east-1:999999999999:layer:vendor-layer"
+	for _, content := range []string{"v1", "v2", "v3"} {
+		f.Publish("us-east-1", vendor, layertest.Version{Description: "vendor layer", Content: []byte(content)})
+	}
+
+	regions := "us-east-1,eu-west-1"
+
+	if err := run(t, f, "mirror", "--regions", regions, vendor+":2", "my-layer"); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if err := run(t, f, "mirror", "--regions", regions, "--all-versions", vendor, "my-layer"); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	for _, region := range []string{"us-east-1", "eu-west-1"} {
+		versions := f.Versions(region, "my-layer")
+		if len(versions) != 3 {
+			t.Fatalf("expected versions '3' in %s, got '%d'", region, len(versions))
+		}
+
+		expected := "vendor layer (mirror of " + vendor + ":2)"
+		if versions[0].Description != expected || string(versions[0].Content) != "v2" {
+			t.Errorf("expected first version '%s' in %s, got '%s'", expected, region, versions[0].Description)
+		}
+	}
+
+	err := run(t, f, "mirror", "--regions", regions, "--versions", "1", "--all-versions", vendor, "my-layer")
+	if code := exitCode(err); code != exitUsage {
+		t.Errorf("expected exit code '%d', got '%d' (%v)", exitUsage, code, err)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		check func(t *testing.T, out string)
+	}{
+		{
+			name: "plain",
+			mode: "plain",
+			check: func(t *testing.T, out string) {
+				for _, line := range []string{
+					"Bumping layer across regions: us-east-1, eu-west-1",
+					"eu-west-1: publishing version 1",
+					"eu-west-1: bump complete",
+				} {
+					if !strings.Contains(out, line+"\n") {
+						t.Errorf("expected line '%s', got:\n%s", line, out)
+					}
+				}
+			},
+		},
+		{
+			name: "json",
+			mode: "json",
+			check: func(t *testing.T, out string) {
+				var stages []string
+
+				dec := json.NewDecoder(strings.NewReader(out))
+				for dec.More() {
+					var e progressEvent
+					if err := dec.Decode(&e); err != nil {
+						t.Fatalf("expected a JSON line, got error %v", err)
+					}
+
+					if e.Type == "progress" && e.Region == "eu-west-1" {
+						stages = append(stages, e.Stage)
+					}
+				}
+
+				expected := []string{layer.StageStart, layer.StageDownload, layer.StagePublish, layer.StageComplete}
+				if !slices.Equal(stages, expected) {
+					t.Errorf("expected stages %v, got %v", expected, stages)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := layertest.New()
+			defer f.Close()
+
+			f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+
+			buf := &bytes.Buffer{}
+
+			app := testApp(t, f.Options())
+			app.Writer = buf
+
+			args := []string{"lb", "--progress", tt.mode, "bump", "--regions", "us-east-1,eu-west-1", "my-layer"}
+			if err := app.RunContext(context.Background(), args); err != nil {
+				t.Fatalf("expected nil, got error %v", err)
+			}
+
+			tt.check(t, buf.String())
+		})
+	}
+
+	f := layertest.New()
+	defer f.Close()
+
+	err := run(t, f, "--progress", "fancy", "bump", "--regions", "us-east-1,eu-west-1", "my-layer")
+	if code := exitCode(err); code != exitUsage {
+		t.Errorf("expected exit code '%d', got '%d' (%v)", exitUsage, code, err)
+	}
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	t.Setenv("NO_COLOR", "yes")
+
+	if err := run(t, f, "bump", "--regions", "us-east-1,eu-west-1", "my-layer"); err != nil {
+		t.Errorf("expected nil with NO_COLOR set, got error %v", err)
+	}
+}
+
+func TestEndpointURL(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v2")})
+
+	app := testApp(t, nil)
+	t.Setenv("LB_ENDPOINT_URL", f.URL())
+
+	args := []string{"lb", "bump", "--regions", "us-east-1,eu-west-1", "my-layer"}
+	if err := app.RunContext(context.Background(), args); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	versions := f.Versions("eu-west-1", "my-layer")
+	if len(versions) != 2 || string(versions[1].Content) != "v2" {
+		t.Errorf("expected versions up to 'v2' published in eu-west-1, got %v", versions)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+
+	app := testApp(t, f.Options())
+
+	args := []string{"lb", "bump", "--regions", "us-east-1,eu-west-1,sa-east-1", "my-layer"}
+	if err := app.RunContext(context.Background(), args); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	app.Writer = buf
+
+	args = []string{"lb", "audit", "--json", "--layer", "my-layer", "--region", "eu-west-1", "--since", "1h"}
+	if err := app.RunContext(context.Background(), args); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	var entries []internal.AuditEntry
+
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var e internal.AuditEntry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("expected a JSON line, got error %v", err)
+		}
+
+		entries = append(entries, e)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("expected entries '1', got '%d'", len(entries))
+	}
+
+	e := entries[0]
+	if e.Command != "bump" || e.Action != layer.ActionPublish || e.Target.Region != "eu-west-1" || e.Target.CodeSha256 == "" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	if e.Identity == nil || e.Identity.Account != layertest.AccountID {
+		t.Errorf("expected the caller identity, got %+v", e.Identity)
+	}
+
+	err := app.RunContext(context.Background(), []string{"lb", "audit", "--action", "update"})
+	if code := exitCode(err); code != exitUsage {
+		t.Errorf("expected exit code '%d', got '%d' (%v)", exitUsage, code, err)
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n internal.Notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Errorf("expected a JSON payload, got error %v", err)
+		}
+
+		mu.Lock()
+		events = append(events, n.Event+": "+n.Message)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	config := filepath.Join(t.TempDir(), "config.json")
+	content := `{"notifications": [{"type": "webhook", "url": "` + srv.URL + `", "events": ["drift_detected", "bump_completed"]}]}`
+
+	if err := os.WriteFile(config, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+
+	for _, cmd := range []string{"verify", "bump", "verify"} {
+		_ = run(t, f, "--config", config, cmd, "--regions", "us-east-1,eu-west-1", "my-layer")
+	}
+
+	expected := []string{
+		"drift_detected: Layer my-layer drift detected: some regions are not bumped: us-east-1 (1), eu-west-1 (0)",
+		"bump_completed: Layer my-layer bumped to version 1 in eu-west-1",
+	}
+
+	if !slices.Equal(events, expected) {
+		t.Errorf("expected events %q, got %q", expected, events)
+	}
+}
+
+func TestBumpSkipsPublishedContent(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v2")})
+	f.Publish("eu-west-1", "my-layer", layertest.Version{Content: []byte("v2")})
+
+	buf := &bytes.Buffer{}
+
+	app := testApp(t, f.Options())
+	app.Writer = buf
+
+	args := []string{"lb", "--progress", "plain", "bump", "--regions", "us-east-1,eu-west-1", "my-layer"}
+	if err := app.RunContext(context.Background(), args); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if line := "eu-west-1: skipping version 2, content already published\n"; !strings.Contains(buf.String(), line) {
+		t.Errorf("expected line '%s', got:\n%s", line, buf)
+	}
+
+	if versions := f.Versions("eu-west-1", "my-layer"); len(versions) != 1 {
+		t.Errorf("expected no version published in eu-west-1, got %d versions", len(versions))
+	}
+
+	args = []string{"lb", "verify", "--regions", "us-east-1,eu-west-1", "my-layer"}
+	if err := app.RunContext(context.Background(), args); err != nil {
+		t.Errorf("expected the regions with the same content balanced, got error %v", err)
+	}
+}
+
+func TestBumpAtomic(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v2")})
+
+	client := &publishLimit{Fake: f}
+	client.limit.Store(1)
+
+	buf := &bytes.Buffer{}
+
+	app := testApp(t, append(f.Options(), layer.WithClient(client)))
+	app.Writer = buf
+
+	args := []string{"lb", "--progress", "plain", "bump", "--atomic", "--regions", "us-east-1,eu-west-1", "my-layer"}
+
+	err := app.RunContext(context.Background(), args)
+	if code := exitCode(err); code != exitFailure {
+		t.Errorf("expected exit code '%d', got '%d' (%v)", exitFailure, code, err)
+	}
+
+	if line := "Rolled back versions: eu-west-1 (1)\n"; !strings.Contains(buf.String(), line) {
+		t.Errorf("expected line '%s', got:\n%s", line, buf)
+	}
+
+	if versions := f.Versions("eu-west-1", "my-layer"); len(versions) != 0 {
+		t.Errorf("expected no versions in eu-west-1, got %d versions", len(versions))
+	}
+
+	buf.Reset()
+
+	if err := app.RunContext(context.Background(), []string{"lb", "audit", "--action", "delete", "--json"}); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"action":"delete"`) {
+		t.Errorf("expected the rollback recorded in the audit journal, got:\n%s", buf)
+	}
+}
+
+func TestBumpAfterRollback(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v2")})
+	f.Publish("eu-west-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("eu-west-1", "my-layer", layertest.Version{Content: []byte("v2")})
+
+	// the version 2 deleted by a rollback is never reused in eu-west-1.
+	_, err := f.DeleteLayerVersion(context.Background(), &lambda.DeleteLayerVersionInput{
+		LayerName:     aws.String("my-layer"),
+		VersionNumber: aws.Int64(2),
+	}, func(o *lambda.Options) { o.Region = "eu-west-1" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	regions := "us-east-1,eu-west-1"
+
+	if err := run(t, f, "bump", "--regions", regions, "my-layer"); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if err := run(t, f, "verify", "--regions", regions, "my-layer"); err != nil {
+		t.Errorf("expected the regions with the same content balanced, got error %v", err)
+	}
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v3")})
+
+	if err := run(t, f, "verify", "--regions", regions, "my-layer"); !errors.Is(err, layer.ErrDrift) {
+		t.Errorf("expected drift of the same numbers with different content, got %v", err)
+	}
+
+	if err := run(t, f, "bump", "--regions", regions, "my-layer"); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	versions := f.Versions("eu-west-1", "my-layer")
+	if latest := versions[len(versions)-1]; latest.Number != 4 || string(latest.Content) != "v3" {
+		t.Errorf("expected the content 'v3' published as version '4', got '%s' as version '%d'", latest.Content, latest.Number)
+	}
+}
+
+func TestBumpSourceGap(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	for _, content := range []string{"v1", "v2", "v3"} {
+		f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte(content)})
+	}
+
+	f.Publish("eu-west-1", "my-layer", layertest.Version{Content: []byte("v1")})
+
+	// the version 2 deleted by a rollback leaves a gap in us-east-1.
+	_, err := f.DeleteLayerVersion(context.Background(), &lambda.DeleteLayerVersionInput{
+		LayerName:     aws.String("my-layer"),
+		VersionNumber: aws.Int64(2),
+	}, func(o *lambda.Options) { o.Region = "us-east-1" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(t, f, "bump", "--regions", "us-east-1,eu-west-1", "my-layer"); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	versions := f.Versions("eu-west-1", "my-layer")
+	if len(versions) != 2 || string(versions[1].Content) != "v3" {
+		t.Errorf("expected the content 'v3' published after 'v1', got %d versions", len(versions))
+	}
+}
+
+func TestBumpAuditLogNotWritable(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := run(t, f, "--audit-log", filepath.Join(file, "audit.jsonl"), "bump", "--regions", "us-east-1,eu-west-1", "my-layer")
+	if err == nil || !strings.Contains(err.Error(), "failed to create audit journal") {
+		t.Errorf("expected the audit journal failure, got %v", err)
+	}
+
+	if versions := f.Versions("eu-west-1", "my-layer"); len(versions) != 0 {
+		t.Errorf("expected no version published in eu-west-1, got %d versions", len(versions))
+	}
+}
+
+func TestBumpRollout(t *testing.T) {
+	dir := t.TempDir()
+	checks := filepath.Join(dir, "checks")
+
+	config := filepath.Join(dir, "config.json")
+	content := `{"rollouts": {"prod": {
+		"waves": [{"regions": ["eu-west-1"]}, {"regions": ["sa-east-1"]}, {"regions": ["*"]}],
+		"health_check": "echo \"$LB_WAVE $LB_REGIONS $LB_VERSION\" >> ` + checks + `; test $LB_WAVE -lt 2"
+	}}}`
+
+	if err := os.WriteFile(config, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+
+	buf := &bytes.Buffer{}
+
+	app := testApp(t, f.Options())
+	app.Writer = buf
+
+	args := []string{
+		"lb", "--config", config, "--progress", "plain",
+		"bump", "--rollout", "prod", "--regions", "us-east-1,eu-west-1,sa-east-1,ap-south-1", "my-layer",
+	}
+
+	err := app.RunContext(context.Background(), args)
+	if code := exitCode(err); code != exitPartial {
+		t.Errorf("expected exit code '%d', got '%d' (%v)", exitPartial, code, err)
+	}
+
+	for _, line := range []string{
+		"Rollout waves: 1) eu-west-1 2) sa-east-1 3) us-east-1, ap-south-1",
+		"wave 1: running health check",
+		"wave 2: running health check",
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("expected line '%s', got:\n%s", line, buf)
+		}
+	}
+
+	data, _ := os.ReadFile(checks)
+	if expected := "1 eu-west-1 1\n2 sa-east-1 1\n"; string(data) != expected {
+		t.Errorf("expected health checks '%s', got '%s'", expected, data)
+	}
+
+	if latest := f.Latest("ap-south-1", "my-layer"); latest != 0 {
+		t.Errorf("expected the rollout stopped before ap-south-1, got version '%d'", latest)
+	}
+
+	err = run(t, f, "--config", config, "bump", "--rollout", "staging", "--regions", "us-east-1,eu-west-1", "my-layer")
+	if code := exitCode(err); code != exitUsage {
+		t.Errorf("expected exit code '%d', got '%d' (%v)", exitUsage, code, err)
+	}
+}
+
+func TestBumpSmokeTest(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+
+	f.Function("eu-west-1", "canary", func(payload []byte, layers []string) ([]byte, error) {
+		return []byte(`{"layers": ` + strconv.Itoa(len(layers)) + `}`), nil
+	})
+	f.Function("sa-east-1", "canary", func(payload []byte, layers []string) ([]byte, error) {
+		return nil, errors.New("import error")
+	})
+
+	config := filepath.Join(t.TempDir(), "config.json")
+	content := `{"smoke_tests": {"my-layer": {"function": "canary", "payload": {"ping": true}, "expect": "\"layers\": 1"}}}`
+
+	if err := os.WriteFile(config, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := testApp(t, nil)
+	t.Setenv("LB_ENDPOINT_URL", f.URL())
+
+	args := []string{"lb", "--config", config, "bump", "--regions", "us-east-1,eu-west-1", "my-layer"}
+	if err := app.RunContext(context.Background(), args); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	expected := []string{"arn:aws:lambda:eu-west-1:" + layertest.AccountID + ":layer:my-layer:1"}
+	if layers := f.FunctionLayers("eu-west-1", "canary"); !slices.Equal(layers, expected) {
+		t.Errorf("expected layers %v, got %v", expected, layers)
+	}
+
+	args = []string{"lb", "--config", config, "bump", "--regions", "us-east-1,sa-east-1", "my-layer"}
+
+	err := app.RunContext(context.Background(), args)
+	if err == nil || !strings.Contains(err.Error(), "sa-east-1: smoke test of version 1 failed: function canary failed (Unhandled)") {
+		t.Errorf("expected the smoke test failure of sa-east-1, got %v", err)
+	}
+
+	// ap-south-1 has no test function.
+	args = []string{"lb", "--config", config, "bump", "--no-smoke-test", "--regions", "us-east-1,ap-south-1", "my-layer"}
+	if err := app.RunContext(context.Background(), args); err != nil {
+		t.Errorf("expected nil, got error %v", err)
+	}
+}
+
+func TestBumpHooks(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+
+	dir := t.TempDir()
+	t.Setenv("HOOKS_DIR", dir)
+
+	content, err := json.Marshal(map[string]any{
+		"hooks": map[string][]string{
+			"post-download": {`cp "$LB_ARTIFACT" "$HOOKS_DIR/$LB_REGION.zip"`},
+			"pre-publish":   {`test "$LB_REGION" != sa-east-1`},
+			"post-publish":  {"exit 1"},
+			"post-bump":     {`echo "$LB_REGION $LB_VERSION" >> "$HOOKS_DIR/bumped"`},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(config, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := testApp(t, nil)
+	t.Setenv("LB_ENDPOINT_URL", f.URL())
+
+	// the post-publish hook failure is only logged.
+	args := []string{"lb", "--config", config, "bump", "--regions", "us-east-1,eu-west-1", "my-layer"}
+	if err := app.RunContext(context.Background(), args); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if artifact, err := os.ReadFile(filepath.Join(dir, "eu-west-1.zip")); err != nil || string(artifact) != "v1" {
+		t.Errorf("expected the artifact copied by the post-download hook, got '%s' (%v)", artifact, err)
+	}
+
+	if bumped, err := os.ReadFile(filepath.Join(dir, "bumped")); err != nil || string(bumped) != "eu-west-1 1\n" {
+		t.Errorf("expected the post-bump hook run once, got '%s' (%v)", bumped, err)
+	}
+
+	args = []string{"lb", "--config", config, "bump", "--regions", "us-east-1,sa-east-1", "my-layer"}
+
+	err = app.RunContext(context.Background(), args)
+	if err == nil || !strings.Contains(err.Error(), `sa-east-1: pre-publish hook failed:`) {
+		t.Errorf("expected the pre-publish hook failure of sa-east-1, got %v", err)
+	}
+
+	if latest := f.Latest("sa-east-1", "my-layer"); latest != 0 {
+		t.Errorf("expected no version published in sa-east-1, got '%d'", latest)
+	}
+
+	args = []string{"lb", "--config", config, "bump", "--no-hooks", "--regions", "us-east-1,sa-east-1", "my-layer"}
+	if err := app.RunContext(context.Background(), args); err != nil {
+		t.Errorf("expected nil, got error %v", err)
+	}
+}
+
+func TestBumpInteractive(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("eu-west-1", "other-layer", layertest.Version{Content: []byte("v1")})
+
+	app := testApp(t, nil)
+	t.Setenv("LB_ENDPOINT_URL", f.URL())
+
+	args := []string{"lb", "bump", "--interactive", "--regions", "us-east-1,eu-west-1"}
+
+	err := app.RunContext(context.Background(), args)
+	if exitCode(err) != exitUsage {
+		t.Errorf("expected a usage error without a terminal, got %v", err)
+	}
+
+	p := &mockPrompter{choice: "my-layer"}
+	app.Metadata[prompterKey] = p
+
+	err = app.RunContext(context.Background(), args)
+	if !errors.Is(err, errCancelled) {
+		t.Errorf("Unexpected error: %v (expected %s)", err, errCancelled)
+	}
+
+	if expected := []string{"my-layer", "other-layer"}; !slices.Equal(p.options, expected) {
+		t.Errorf("expected layer options %v, got %v", expected, p.options)
+	}
+
+	if expected := "Publish version 1 into 1 regions?"; p.prompted[len(p.prompted)-1] != expected {
+		t.Errorf("expected confirmation '%s', got %v", expected, p.prompted)
+	}
+
+	if latest := f.Latest("eu-west-1", "my-layer"); latest != 0 {
+		t.Errorf("expected no version published when cancelled, got '%d'", latest)
+	}
+
+	p.confirm = true
+
+	if err := app.RunContext(context.Background(), args); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if latest := f.Latest("eu-west-1", "my-layer"); latest != 1 {
+		t.Errorf("expected version '1' published in eu-west-1, got '%d'", latest)
+	}
+
+	p.prompted = nil
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v2")})
+
+	args = []string{"lb", "bump", "--yes", "--regions", "us-east-1,eu-west-1", "my-layer"}
+	if err := app.RunContext(context.Background(), args); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if len(p.prompted) != 0 {
+		t.Errorf("expected no prompts with --yes, got %v", p.prompted)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("eu-west-1", "other-layer", layertest.Version{Content: []byte("v1")})
+
+	config := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(config, []byte(`{"region_groups": {"europe": ["eu-west-1"]}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := testApp(t, nil)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("LB_ENDPOINT_URL", f.URL())
+
+	complete := func(t *testing.T, words ...string) []string {
+		t.Helper()
+
+		out := &bytes.Buffer{}
+		app.Writer = out
+
+		if err := app.RunContext(context.Background(), append([]string{"lb", "__complete"}, words...)); err != nil {
+			t.Fatalf("expected nil, got error %v", err)
+		}
+
+		return strings.Fields(out.String())
+	}
+
+	tests := []struct {
+		name     string
+		words    []string
+		expected []string
+	}{
+		{
+			name:     "commands",
+			words:    []string{"re"},
+			expected: []string{"replicate"},
+		},
+		{
+			name:     "flags",
+			words:    []string{"bump", "--al"},
+			expected: []string{"--all-regions"},
+		},
+		{
+			name:     "regions",
+			words:    []string{"bump", "--regions", "us-east-1,us-west"},
+			expected: []string{"us-east-1,us-west-1", "us-east-1,us-west-2"},
+		},
+		{
+			name:     "listed regions",
+			words:    []string{"verify", "-r=sa-east-1,sa"},
+			expected: nil,
+		},
+		{
+			name:     "bash split region groups",
+			words:    []string{"--config", config, "bump", "--exclude-regions", "=", "@"},
+			expected: []string{"@europe"},
+		},
+		{
+			name:     "layer names",
+			words:    []string{"--config", config, "bump", "--regions", "us-east-1,@europe", ""},
+			expected: []string{"my-layer", "other-layer"},
+		},
+		{
+			name:     "layer names of the config region",
+			words:    []string{"verify", "my"},
+			expected: []string{"my-layer"},
+		},
+		{
+			name:     "second argument",
+			words:    []string{"verify", "my-layer", ""},
+			expected: nil,
+		},
+		{
+			name:     "mirror layer name",
+			words:    []string{"mirror", "arn:aws:lambda:us-east-1:111111111111:layer:vendor:1", "my"},
+			expected: []string{"my-layer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := complete(t, tt.words...); !slices.Equal(got, tt.expected) {
+				t.Errorf("expected candidates %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("typed global flags", func(t *testing.T) {
+		typed := layertest.New()
+		defer typed.Close()
+
+		typed.Publish("us-east-1", "typed-layer", layertest.Version{Content: []byte("v1")})
+
+		if got := complete(t, "--endpoint-url", typed.URL(), "verify", ""); !slices.Equal(got, []string{"typed-layer"}) {
+			t.Errorf("expected the layer names of the typed endpoint, got %v", got)
+		}
+	})
+
+	t.Run("cached layer names", func(t *testing.T) {
+		f.Publish("us-east-1", "new-layer", layertest.Version{Content: []byte("v1")})
+
+		if got := complete(t, "verify", ""); !slices.Equal(got, []string{"my-layer"}) {
+			t.Errorf("expected the cached layer names, got %v", got)
+		}
+
+		t.Setenv("LB_COMPLETION_CACHE_TTL", "0s")
+
+		if got := complete(t, "verify", ""); !slices.Equal(got, []string{"my-layer", "new-layer"}) {
+			t.Errorf("expected the listed layer names, got %v", got)
+		}
+	})
+}
+
+func TestCompletionScripts(t *testing.T) {
+	app := testApp(t, nil)
+
+	for _, shell := range completionShells {
+		out := &bytes.Buffer{}
+		app.Writer = out
+
+		if err := app.RunContext(context.Background(), []string{"lb", "completion", shell}); err != nil {
+			t.Fatalf("expected nil, got error %v", err)
+		}
+
+		if !strings.Contains(out.String(), "lb __complete") {
+			t.Errorf("expected the %s script calling the completion command, got %s", shell, out)
+		}
+	}
+
+	err := app.RunContext(context.Background(), []string{"lb", "completion", "powershell"})
+
+	expected := `unsupported shell "powershell", expected one of: bash, zsh, fish`
+	if err == nil || err.Error() != expected || exitCode(err) != exitUsage {
+		t.Errorf("Unexpected error: %v (expected %s)", err, expected)
+	}
+}
+
+func TestLayers(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("eu-west-1", "my-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("eu-west-1", "other-layer", layertest.Version{Content: []byte("v1")})
+
+	// eu-west-1 has the same content, with a version skipped.
+	f.Publish("us-east-1", "skipped-layer", layertest.Version{Content: []byte("v1")})
+	f.Publish("us-east-1", "skipped-layer", layertest.Version{Content: []byte("v2")})
+	f.Publish("eu-west-1", "skipped-layer", layertest.Version{Content: []byte("v2")})
+
+	app := testApp(t, f.Options())
+
+	layers := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+
+		out := &bytes.Buffer{}
+		app.Writer = out
+
+		err := app.RunContext(context.Background(), append([]string{"lb", "layers", "--regions", "us-east-1,eu-west-1"}, args...))
+
+		return out.String(), err
+	}
+
+	out, err := layers(t)
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	expected := `LAYER          STATUS    LATEST  REGIONS
+my-layer       balanced  1       us-east-1 (1), eu-west-1 (1)
+other-layer    drift     1       us-east-1 (-), eu-west-1 (1)
+skipped-layer  balanced  2       us-east-1 (2), eu-west-1 (1)
+`
+	if out != expected {
+		t.Errorf("expected table:\n%s\ngot:\n%s", expected, out)
+	}
+
+	out, err = layers(t, "--json")
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	var overviews []internal.LayerOverview
+
+	dec := json.NewDecoder(strings.NewReader(out))
+	for dec.More() {
+		o := internal.LayerOverview{}
+		if err := dec.Decode(&o); err != nil {
+			t.Fatalf("expected JSON lines, got error %v", err)
+		}
+
+		overviews = append(overviews, o)
+	}
+
+	if len(overviews) != 3 || overviews[1].Layer != "other-layer" || overviews[1].Status != internal.StatusDrift {
+		t.Errorf("unexpected layers %+v", overviews)
+	}
+
+	_, err = layers(t, "--verify")
+	if exitCode(err) != exitDrift || !strings.HasPrefix(err.Error(), "other-layer: ") {
+		t.Errorf("expected the drift of other-layer, got %v", err)
+	}
+
+	f.Fail(layertest.OpListLayers, "eu-west-1", errors.New("access denied"), 1)
+
+	_, err = layers(t)
+	if err == nil || !strings.Contains(err.Error(), "eu-west-1: unable to list layers") {
+		t.Errorf("expected the list failure of eu-west-1, got %v", err)
+	}
+}
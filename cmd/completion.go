@@ -0,0 +1,409 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+const (
+	// completionCacheTTL is the default duration the listed layer names are
+	// cached for the completion, LB_COMPLETION_CACHE_TTL overrides it.
+	completionCacheTTL = 5 * time.Minute
+
+	// completionTimeout is the maximum duration of listing the layer names.
+	completionTimeout = 5 * time.Second
+)
+
+// completionScripts are the completion scripts by shell, calling the hidden
+// __complete command with the words of the command line.
+var completionScripts = map[string]string{
+	"bash": `# bash completion of %[1]s, load with: source <(%[1]s completion bash)
+_%[1]s_completion() {
+	local IFS=$'\n'
+	COMPREPLY=($(%[1]s __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+
+complete -o default -F _%[1]s_completion %[1]s
+`,
+	"zsh": `#compdef %[1]s
+# zsh completion of %[1]s, load with: source <(%[1]s completion zsh)
+_%[1]s() {
+	local -a candidates
+	candidates=(${(f)"$(%[1]s __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+
+	if (( ${#candidates} )); then
+		compadd -- "${candidates[@]}"
+	else
+		_files
+	fi
+}
+
+compdef _%[1]s %[1]s
+`,
+	"fish": `# fish completion of %[1]s, load with: %[1]s completion fish | source
+function __%[1]s_complete
+	set -l tokens (commandline -opc)
+	set -l cur (commandline -ct)
+	%[1]s __complete $tokens[2..-1] "$cur" 2>/dev/null
+end
+
+complete -c %[1]s -f -a '(__%[1]s_complete)'
+`,
+}
+
+// completionShells are the shells with completion scripts.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+var completionCmd = &cli.Command{
+	Name:        "completion",
+	Description: "prints the shell completion script of bash, zsh or fish",
+	ArgsUsage:   "shell",
+	Action: func(cc *cli.Context) error {
+		shell := cc.Args().First()
+		if shell == "" {
+			return usageErrorf(`required argument "shell" not set`)
+		}
+
+		script, ok := completionScripts[shell]
+		if !ok {
+			return usageErrorf("unsupported shell %q, expected one of: %s", shell, strings.Join(completionShells, ", "))
+		}
+
+		_, err := fmt.Fprintf(cc.App.Writer, script, cc.App.Name)
+
+		return err
+	},
+}
+
+var completeCmd = &cli.Command{
+	Name:            "__complete",
+	Description:     "prints the candidates completing the last word of the command line",
+	Hidden:          true,
+	HideHelp:        true,
+	SkipFlagParsing: true,
+	Action: func(cc *cli.Context) error {
+		words := cc.Args().Slice()
+		if len(words) == 0 {
+			words = []string{""}
+		}
+
+		for _, candidate := range complete(cc, words) {
+			fmt.Fprintln(cc.App.Writer, candidate)
+		}
+
+		return nil
+	},
+}
+
+// commandLine is the command line parsed for the completion.
+type commandLine struct {
+	// cmd is the command, nil when not typed yet.
+	cmd *cli.Command
+
+	// flags are the flags available at the end of the command line.
+	flags []cli.Flag
+
+	// flag is the flag whose value is being completed.
+	flag cli.Flag
+
+	// values are the values of the flags by flag name, the values of a
+	// repeated list flag are joined by commas.
+	values map[string]string
+
+	// args is the number of the command arguments.
+	args int
+}
+
+// parseCommandLine parses the words of the command line.
+func parseCommandLine(app *cli.App, words []string) *commandLine {
+	cl := &commandLine{flags: app.Flags, values: map[string]string{}}
+
+	for _, w := range words {
+		if cl.flag != nil {
+			cl.set(cl.flag, w)
+			cl.flag = nil
+
+			continue
+		}
+
+		if strings.HasPrefix(w, "-") && w != "-" {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(w, "-"), "=")
+
+			f := findFlag(cl.flags, name)
+			if f == nil || !takesValue(f) {
+				continue
+			}
+
+			if hasValue {
+				cl.set(f, value)
+			} else {
+				cl.flag = f
+			}
+
+			continue
+		}
+
+		if cl.cmd == nil {
+			if cl.cmd = app.Command(w); cl.cmd != nil {
+				cl.flags = cl.cmd.Flags
+			}
+
+			continue
+		}
+
+		cl.args++
+	}
+
+	return cl
+}
+
+// set sets the value of the flag, appending it to the previous values of a
+// list flag.
+func (cl *commandLine) set(f cli.Flag, value string) {
+	name := f.Names()[0]
+
+	if _, ok := f.(*cli.StringSliceFlag); ok && cl.values[name] != "" {
+		value = cl.values[name] + "," + value
+	}
+
+	cl.values[name] = value
+}
+
+// slice returns the values of the list flag typed on the command line, or
+// the values of the context when not typed, e.g. set by the environment.
+func (cl *commandLine) slice(cc *cli.Context, name string) []string {
+	if value := cl.values[name]; value != "" {
+		return strings.Split(value, ",")
+	}
+
+	return cc.StringSlice(name)
+}
+
+// complete returns the candidates completing the last word of the command line.
+func complete(cc *cli.Context, words []string) []string {
+	cur := words[len(words)-1]
+	words = slices.Clone(words[:len(words)-1])
+
+	// bash splits the words at "=", while the other shells keep the flag and
+	// its value in the same word.
+	if n := len(words); n > 1 && words[n-1] == "=" {
+		words = words[:n-1]
+	}
+
+	prefix := ""
+	if name, value, ok := strings.Cut(cur, "="); ok && strings.HasPrefix(name, "-") {
+		words = append(words, name)
+		prefix, cur = name+"=", value
+	}
+
+	cl := parseCommandLine(cc.App, words)
+
+	var candidates []string
+
+	switch {
+	case cl.flag != nil:
+		prefix, cur, candidates = completeFlagValue(cc, cl, prefix, cur)
+	case strings.HasPrefix(cur, "-"):
+		candidates = flagNames(cl.flags)
+	case cl.cmd == nil:
+		candidates = commandNames(cc.App.Commands)
+	case layerArg(cl.cmd, cl.args):
+		candidates = layerNames(cc, cl)
+	}
+
+	var matches []string
+
+	for _, c := range candidates {
+		if strings.HasPrefix(c, cur) {
+			matches = append(matches, prefix+c)
+		}
+	}
+
+	return matches
+}
+
+// completeFlagValue returns the candidates of the flag value, the values of a
+// list are completed after the last comma.
+func completeFlagValue(cc *cli.Context, cl *commandLine, prefix, cur string) (string, string, []string) {
+	name := cl.flag.Names()[0]
+
+	switch name {
+	case "regions", "exclude-regions", "source-region", "region":
+		if _, ok := cl.flag.(*cli.StringSliceFlag); !ok {
+			return prefix, cur, regionNames(cc, cl, nil)
+		}
+
+		i := strings.LastIndex(cur, ",")
+
+		return prefix + cur[:i+1], cur[i+1:], regionNames(cc, cl, strings.Split(cur[:i+1], ","))
+	case "layer":
+		return prefix, cur, layerNames(cc, cl)
+	case "progress":
+		return prefix, cur, progressModes
+	}
+
+	return prefix, cur, nil
+}
+
+// regionNames returns the known regions and the region groups of the
+// configuration file, except the listed ones.
+func regionNames(cc *cli.Context, cl *commandLine, listed []string) []string {
+	names := slices.Clone(internal.KnownRegions)
+
+	for group := range completionConfig(cc, cl).RegionGroups {
+		names = append(names, "@"+group)
+	}
+
+	slices.Sort(names)
+
+	return slices.DeleteFunc(names, func(name string) bool {
+		return slices.Contains(listed, name)
+	})
+}
+
+// layerNames returns the layer names of the regions of the command line, or of
+// the aws config region, using the names cached by the completion. The global
+// flags typed on the command line take precedence, as they are not parsed.
+func layerNames(cc *cli.Context, cl *commandLine) []string {
+	ctx, cancel := context.WithTimeout(cc.Context, completionTimeout)
+	defer cancel()
+
+	profile := cmp.Or(cl.values["profile"], cc.String("profile"))
+	endpoints := cl.slice(cc, "endpoint-url")
+
+	cfg, err := internal.LoadAWSConfig(ctx, internal.Credentials{Profile: profile})
+	if err != nil {
+		return nil
+	}
+
+	var regions []string
+
+	if value := cl.values["regions"]; value != "" {
+		regions, _ = internal.ExpandRegions(strings.Split(value, ","), completionConfig(cc, cl).RegionGroups)
+	}
+
+	if len(regions) == 0 && cfg.Region != "" {
+		regions = []string{cfg.Region}
+	}
+
+	opts, err := endpointOptions(cc, endpoints, cl.slice(cc, "download-endpoint-url"))
+	if err != nil || len(regions) == 0 {
+		return nil
+	}
+
+	ttl, err := time.ParseDuration(os.Getenv("LB_COMPLETION_CACHE_TTL"))
+	if err != nil {
+		ttl = completionCacheTTL
+	}
+
+	cache := internal.NewNameCache(internal.DefaultCompletionCachePath(), ttl)
+	key := strings.Join([]string{profile, strings.Join(regions, ","), strings.Join(endpoints, ",")}, "|")
+
+	names, _ := cache.Names(ctx, key, func(ctx context.Context) ([]string, error) {
+		layers, err := layer.ListLayers(ctx, cfg, regions, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, len(layers))
+		for i, l := range layers {
+			names[i] = l.Name
+		}
+
+		return names, nil
+	})
+
+	return names
+}
+
+// completionConfig returns the configuration file of the command line, an
+// invalid file results in an empty configuration.
+func completionConfig(cc *cli.Context, cl *commandLine) *internal.Config {
+	conf, err := internal.LoadConfig(cmp.Or(cl.values["config"], cc.Path("config")))
+	if err != nil {
+		return &internal.Config{}
+	}
+
+	return conf
+}
+
+// layerArg reports whether the argument at the index is a layer name,
+// according to the arguments usage of the command.
+func layerArg(cmd *cli.Command, index int) bool {
+	for i, arg := range strings.Fields(cmd.ArgsUsage) {
+		name := strings.Trim(arg, "[]")
+		variadic := strings.HasSuffix(name, "...")
+
+		if strings.TrimSuffix(name, "...") == "layer-name" && (i == index || variadic && index > i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findFlag returns the flag of the name, or nil when not found.
+func findFlag(flags []cli.Flag, name string) cli.Flag {
+	for _, f := range flags {
+		if slices.Contains(f.Names(), name) {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// takesValue reports whether the flag requires a value.
+func takesValue(f cli.Flag) bool {
+	df, ok := f.(cli.DocGenerationFlag)
+	return ok && df.TakesValue()
+}
+
+// flagNames returns the names of the flags with their dashes.
+func flagNames(flags []cli.Flag) []string {
+	var names []string
+
+	for _, f := range flags {
+		if vf, ok := f.(cli.VisibleFlag); ok && !vf.IsVisible() {
+			continue
+		}
+
+		for _, name := range f.Names() {
+			if len(name) == 1 {
+				names = append(names, "-"+name)
+			} else {
+				names = append(names, "--"+name)
+			}
+		}
+	}
+
+	return names
+}
+
+// commandNames returns the names of the visible commands.
+func commandNames(cmds []*cli.Command) []string {
+	var names []string
+
+	for _, c := range cmds {
+		if !c.Hidden {
+			names = append(names, c.Names()...)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,29 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+)
+
+// loadConfig loads the configuration file defined by the global flag, the file
+// must exist only when the flag was explicitly set.
+func loadConfig(cc *cli.Context) (*internal.Config, error) {
+	path := cc.Path("config")
+
+	if cc.IsSet("config") {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("unable to read config file: %w", err)
+		}
+	}
+
+	return internal.LoadConfig(path)
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+// exit codes returned by the application.
+const (
+	exitOK = iota
+	exitFailure
+	exitUsage
+	exitDrift
+	exitNoVersions
+	exitAuth
+	exitNetwork
+	exitPartial
+)
+
+// usageError represents an invalid usage of a command, like missing arguments.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// usageErrorf creates a usage error according to a format specifier.
+func usageErrorf(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// onUsageError turns the flag parsing errors of the app and its commands into
+// usage errors.
+func onUsageError(_ *cli.Context, err error, _ bool) error {
+	return usageErrorf("incorrect usage: %v", err)
+}
+
+// exitCode maps the error to the documented exit codes.
+func exitCode(err error) int {
+	var (
+		usage   *usageError
+		partial *layer.PartialError
+	)
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usage):
+		return exitUsage
+	case errors.As(err, &partial):
+		return exitPartial
+	case errors.Is(err, layer.ErrDrift):
+		return exitDrift
+	case errors.Is(err, layer.ErrNoVersions):
+		return exitNoVersions
+	case internal.IsAuthError(err):
+		return exitAuth
+	case internal.IsNetworkError(err):
+		return exitNetwork
+	default:
+		return exitFailure
+	}
+}
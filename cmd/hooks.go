@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+// hookOptions returns the option running the hook commands defined in the
+// configuration file, if any. A failure of a pre hook aborts the region, while
+// a failure of a post hook is only logged.
+func hookOptions(cc *cli.Context, logger *slog.Logger) ([]layer.Option, error) {
+	if cc.Bool("no-hooks") {
+		return nil, nil
+	}
+
+	conf, err := loadConfig(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(conf.Hooks) == 0 {
+		return nil, nil
+	}
+
+	runner, err := internal.NewHookRunner(conf.Hooks, cc.App.ErrWriter)
+	if err != nil {
+		return nil, err
+	}
+
+	hook := func(ctx context.Context, hc *layer.HookContext) error {
+		err := runner.Run(ctx, hc)
+		if err == nil || strings.HasPrefix(hc.Hook, "pre-") {
+			return err
+		}
+
+		logger.WarnContext(ctx, "hook failed", "hook", hc.Hook, "region", hc.Region, "error", err)
+
+		return nil
+	}
+
+	return []layer.Option{layer.WithHooks(hook)}, nil
+}
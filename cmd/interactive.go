@@ -0,0 +1,144 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+// prompterKey is the app metadata key holding the prompter, e.g. a scripted
+// prompter for testing.
+const prompterKey = "prompter"
+
+// errCancelled is returned when the confirmation is declined.
+var errCancelled = errors.New("bump cancelled")
+
+// prompter asks the user to pick options and to confirm actions.
+type prompter interface {
+	Select(text string, options []string) (string, error)
+	MultiSelect(text string, options []string) ([]string, error)
+	Confirm(text string) (bool, error)
+}
+
+// termPrompter prompts in the terminal with the pterm interactive printers.
+type termPrompter struct{}
+
+// Select asks to pick one of the options.
+func (termPrompter) Select(text string, options []string) (string, error) {
+	return pterm.DefaultInteractiveSelect.
+		WithOptions(options).
+		WithMaxHeight(15).
+		Show(text)
+}
+
+// MultiSelect asks to pick any of the options.
+func (termPrompter) MultiSelect(text string, options []string) ([]string, error) {
+	return pterm.DefaultInteractiveMultiselect.
+		WithOptions(options).
+		WithMaxHeight(15).
+		Show(text)
+}
+
+// Confirm asks to confirm the action, defaulting to no.
+func (termPrompter) Confirm(text string) (bool, error) {
+	return pterm.DefaultInteractiveConfirm.
+		WithDefaultValue(false).
+		Show(text)
+}
+
+// newPrompter returns the prompter of the app, or the terminal prompter when
+// both the input and the output are terminals, otherwise nil.
+func newPrompter(cc *cli.Context) prompter {
+	if p, ok := cc.App.Metadata[prompterKey].(prompter); ok {
+		return p
+	}
+
+	if isTerminal(os.Stdin) && isTerminal(cc.App.Writer) {
+		return termPrompter{}
+	}
+
+	return nil
+}
+
+// selectRegions returns the regions picked from the regions enabled for the
+// account, unless they are defined by the flags.
+func selectRegions(cc *cli.Context, cfg aws.Config, p prompter) ([]string, error) {
+	if p == nil || cc.IsSet("regions") || cc.Bool("all-regions") {
+		return resolveRegions(cc, cfg)
+	}
+
+	all, err := internal.LoadAccount(cfg).Regions(cc.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	regions, err := p.MultiSelect("Select the regions", all.Enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(regions) <= 1 {
+		return nil, usageErrorf("at least two regions must be selected")
+	}
+
+	return regions, nil
+}
+
+// selectLayer returns the layer picked from the layers of the regions.
+func selectLayer(cc *cli.Context, cfg aws.Config, prog progressPrinter, p prompter, regions []string, opts []layer.Option) (string, error) {
+	stop := prog.Status("listing layers...")
+	layers, err := layer.ListLayers(cc.Context, cfg, regions, opts...)
+
+	stop()
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(layers) == 0 {
+		return "", fmt.Errorf("no layers found in regions %s", strings.Join(regions, ", "))
+	}
+
+	names := make([]string, len(layers))
+	for i, l := range layers {
+		names[i] = l.Name
+	}
+
+	return p.Select("Select the layer", names)
+}
+
+// confirmPlan shows the versions the plan publishes in each region behind and
+// asks to confirm it.
+func confirmPlan(prog progressPrinter, p prompter, plan *layer.Plan) error {
+	behind := plan.Behind()
+
+	prog.Printf("Plan:")
+
+	for _, v := range behind {
+		prog.Printf("  %s: version %d -> %d", v.Region, v.Number, plan.Source.Number)
+	}
+
+	ok, err := p.Confirm(fmt.Sprintf("Publish version %d into %d regions?", plan.Source.Number, len(behind)))
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errCancelled
+	}
+
+	return nil
+}
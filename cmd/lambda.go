@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+
+	lambdaruntime "github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+// IsLambda reports whether the app is running in the AWS Lambda runtime.
+func IsLambda() bool {
+	return os.Getenv("AWS_LAMBDA_RUNTIME_API") != ""
+}
+
+// StartLambda handles the lambda function invocations until the runtime stops.
+func StartLambda(ctx context.Context) {
+	logger, err := internal.NewLogger(os.Stderr, cmp.Or(os.Getenv("LB_LOG_LEVEL"), "info"), cmp.Or(os.Getenv("LB_LOG_FORMAT"), internal.LogJSON))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	cfg, err := internal.LoadAWSConfig(ctx, internal.Credentials{
+		RoleArn:         os.Getenv("LB_ROLE_ARN"),
+		ExternalID:      os.Getenv("LB_EXTERNAL_ID"),
+		RoleSessionName: os.Getenv("LB_ROLE_SESSION_NAME"),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	cfg.APIOptions = append(cfg.APIOptions, internal.LogAPICalls(logger))
+
+	identity, err := callerIdentity(ctx, internal.NewSTSClient(cfg, ""), logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	conf, err := internal.LoadConfig(os.Getenv("LB_CONFIG"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	endpoints, err := internal.ParseEndpoints([]string{os.Getenv("LB_ENDPOINT_URL")})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	downloads, err := internal.ParseEndpoints([]string{os.Getenv("LB_DOWNLOAD_ENDPOINT_URL")})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	opts := []layer.Option{layer.WithEndpoints(endpoints), layer.WithDownloadEndpoints(downloads)}
+
+	// the home directory is not writable in the lambda runtime, so the audit
+	// journal is only recorded when its path is set.
+	if path := os.Getenv("LB_AUDIT_LOG"); path != "" {
+		journal := internal.NewJournal(path)
+		if err := journal.Check(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(exitUsage)
+		}
+
+		opts = append(opts, layer.WithRecorder(journal.Recorder("lambda", identity)))
+	}
+
+	h := &internal.Handler{
+		Load: func(name string) *layer.Balancer {
+			return layer.NewBalancer(cfg, name, opts...)
+		},
+		RegionGroups: conf.RegionGroups,
+	}
+
+	lambdaruntime.StartWithOptions(h.Handle, lambdaruntime.WithContext(ctx))
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+// verifyConcurrency is the maximum number of layers verified at once.
+const verifyConcurrency = 4
+
+var layersCmd = &cli.Command{
+	Name:        "layers",
+	Description: "lists the layers across regions with their latest versions",
+	Flags: slices.Concat(regionFlags, []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "verify",
+			Usage: "verifies the latest versions of every layer, like verify, failing when any layer drifted.",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "write the layers as JSON lines.",
+		},
+	}),
+	Action: func(cc *cli.Context) error {
+		logger, err := newLogger(cc, internal.LogText)
+		if err != nil {
+			return err
+		}
+
+		opts, err := layerOptions(cc)
+		if err != nil {
+			return err
+		}
+
+		cfg, identity, err := loadAWSConfig(cc, logger)
+		if err != nil {
+			return err
+		}
+
+		printIdentity(cc, identity)
+
+		regions, err := resolveRegions(cc, cfg)
+		if err != nil {
+			return err
+		}
+
+		layers, err := layer.ListLayers(cc.Context, cfg, regions, opts...)
+		if err != nil {
+			return err
+		}
+
+		overviews := make([]*internal.LayerOverview, len(layers))
+		errs := make([]error, len(layers))
+
+		if !cc.Bool("verify") {
+			g := &errgroup.Group{}
+			g.SetLimit(verifyConcurrency)
+
+			for i, l := range layers {
+				g.Go(func() error {
+					// the listed versions have no checksum to compare their content.
+					versions := l.Latest(regions)
+
+					err := layer.New(cfg, l.Name, opts...).Checksums(cc.Context, versions)
+					if err == nil {
+						err = layer.CheckBalance(versions)
+					}
+
+					overviews[i] = internal.NewLayerOverview(l.Name, versions, err)
+
+					return nil
+				})
+			}
+
+			_ = g.Wait()
+		}
+
+		if cc.Bool("verify") {
+			notifier, err := newNotifier(cc, logger)
+			if err != nil {
+				return err
+			}
+
+			g := &errgroup.Group{}
+			g.SetLimit(verifyConcurrency)
+
+			for i, l := range layers {
+				g.Go(func() error {
+					versions, err := layer.NewBalancer(cfg, l.Name, opts...).Verify(cc.Context, regions)
+					if err != nil {
+						errs[i] = fmt.Errorf("%s: %w", l.Name, err)
+					}
+
+					if versions == nil {
+						versions = l.Latest(regions)
+					}
+
+					overviews[i] = internal.NewLayerOverview(l.Name, versions, err)
+					notifier.send(cc.Context, internal.NewNotification(verifyEvent(err), l.Name, versions, err))
+
+					return nil
+				})
+			}
+
+			_ = g.Wait()
+		}
+
+		if err := writeLayers(cc, overviews); err != nil {
+			return err
+		}
+
+		balanced := 0
+		for _, o := range overviews {
+			if o.Status == internal.StatusBalanced {
+				balanced++
+			}
+		}
+
+		fmt.Fprintf(cc.App.ErrWriter, "%d of %d layers balanced\n", balanced, len(overviews))
+
+		return errors.Join(errs...)
+	},
+}
+
+// writeLayers writes the overviews as a table, or as JSON lines.
+func writeLayers(cc *cli.Context, overviews []*internal.LayerOverview) error {
+	if !cc.Bool("json") {
+		return internal.WriteLayers(cc.App.Writer, overviews)
+	}
+
+	enc := json.NewEncoder(cc.App.Writer)
+	for _, o := range overviews {
+		if err := enc.Encode(o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
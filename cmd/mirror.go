@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+var mirrorCmd = &cli.Command{
+	Name:        "mirror",
+	Description: "mirrors a layer of another account into a layer across regions",
+	Flags: slices.Concat(regionFlags, []cli.Flag{
+		&cli.Int64SliceFlag{
+			Name:  "versions",
+			Usage: "list of upstream versions separated by comma, defaults to the arn version or the latest version.",
+		},
+		&cli.BoolFlag{
+			Name:  "all-versions",
+			Usage: "mirror all upstream versions.",
+		},
+	}),
+	ArgsUsage: "layer-arn layer-name",
+	Action: func(cc *cli.Context) error {
+		if cc.NArg() != 2 {
+			return usageErrorf(`required arguments "layer-arn" and "layer-name" not set`)
+		}
+
+		src, err := layer.ParseArn(cc.Args().Get(0))
+		if err != nil {
+			return usageErrorf("%v", err)
+		}
+
+		name := cc.Args().Get(1)
+
+		versions := cc.Int64Slice("versions")
+		if len(versions) > 0 && cc.Bool("all-versions") {
+			return usageErrorf(`flags "versions" and "all-versions" are mutually exclusive`)
+		}
+
+		prog, err := newProgress(cc)
+		if err != nil {
+			return err
+		}
+
+		logger, err := newLogger(cc, internal.LogText)
+		if err != nil {
+			return err
+		}
+
+		opts, err := layerOptions(cc)
+		if err != nil {
+			return err
+		}
+
+		cfg, identity, err := loadAWSConfig(cc, logger)
+		if err != nil {
+			return err
+		}
+
+		printIdentity(cc, identity)
+
+		regions, err := resolveRegions(cc, cfg)
+		if err != nil {
+			return err
+		}
+
+		upstream := layer.New(cfg, src.LayerArn(), opts...)
+
+		stop := prog.Status("getting upstream versions...")
+		versions, err = upstreamVersions(cc, upstream, src, versions)
+
+		stop()
+
+		if err != nil {
+			return err
+		}
+
+		prog.Printf(
+			"Mirroring %s versions %s into regions: %s",
+			src.LayerArn(),
+			pterm.Green(joinVersions(versions)),
+			pterm.Green(strings.Join(regions, ", ")),
+		)
+
+		audit, err := auditOption(cc, identity)
+		if err != nil {
+			return err
+		}
+
+		b := layer.NewBalancer(cfg, name, append(
+			opts,
+			layer.WithProgress(prog.Update),
+			audit,
+		)...)
+
+		if err := prog.Start(regions); err != nil {
+			return err
+		}
+
+		if err := b.Mirror(cc.Context, upstream, versions, regions); err != nil {
+			_ = prog.Stop()
+			return err
+		}
+
+		return prog.Stop()
+	},
+}
+
+// upstreamVersions returns the upstream versions to mirror: the selected ones,
+// all versions, the arn version or the latest version.
+func upstreamVersions(cc *cli.Context, upstream *layer.Layer, src *layer.Arn, selected []int64) ([]int64, error) {
+	switch {
+	case len(selected) > 0:
+		return selected, nil
+	case cc.Bool("all-versions"):
+		all, err := upstream.ListVersions(cc.Context, src.Region)
+		if err != nil {
+			return nil, err
+		}
+
+		versions := make([]int64, len(all))
+		for i, v := range all {
+			versions[i] = v.Number
+		}
+
+		if len(versions) == 0 {
+			return nil, layer.ErrNoVersions
+		}
+
+		return versions, nil
+	case src.Version > 0:
+		return []int64{src.Version}, nil
+	}
+
+	latest, err := upstream.LatestVersion(cc.Context, src.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	if latest.Number == 0 {
+		return nil, layer.ErrNoVersions
+	}
+
+	return []int64{latest.Number}, nil
+}
+
+// joinVersions returns the versions separated by comma.
+func joinVersions(versions []int64) string {
+	items := make([]string, len(versions))
+	for i, v := range versions {
+		items[i] = strconv.FormatInt(v, 10)
+	}
+
+	return strings.Join(items, ", ")
+}
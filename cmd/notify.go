@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+)
+
+// notifyTimeout is the maximum duration of sending a notification to all sinks.
+const notifyTimeout = 10 * time.Second
+
+// notifier sends the command results to the notification sinks of the
+// configuration file, logging the failures.
+type notifier struct {
+	*internal.Notifier
+	logger *slog.Logger
+}
+
+// newNotifier creates the notifier of the notification sinks defined in the
+// configuration file.
+func newNotifier(cc *cli.Context, logger *slog.Logger) (*notifier, error) {
+	conf, err := loadConfig(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := internal.NewNotifier(conf.Notifications, &http.Client{Timeout: notifyTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifier{Notifier: n, logger: logger}, nil
+}
+
+// send sends the notification, a failure does not change the command result
+// and is only logged.
+func (n *notifier) send(ctx context.Context, notification *internal.Notification) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyTimeout)
+	defer cancel()
+
+	if err := n.Notify(ctx, notification); err != nil {
+		n.logger.WarnContext(ctx, "notification failed", "event", notification.Event, "error", err)
+	}
+}
@@ -0,0 +1,335 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// progress modes.
+const (
+	progressAuto        = "auto"
+	progressInteractive = "interactive"
+	progressPlain       = "plain"
+	progressJSON        = "json"
+)
+
+// progressModes lists the supported progress modes.
+var progressModes = []string{progressAuto, progressInteractive, progressPlain, progressJSON}
+
+// progressPrinter renders the command messages and the progress events.
+type progressPrinter interface {
+	// Printf prints a message of the command.
+	Printf(format string, args ...any)
+
+	// Status shows the status of a command step, until stop is called.
+	Status(text string) (stop func())
+
+	// Start starts rendering the progress of each region.
+	Start(regions []string) error
+
+	// Update renders the progress event, it is safe for concurrent use.
+	Update(e layer.Event)
+
+	// Stop stops rendering the progress.
+	Stop() error
+}
+
+// newProgress creates the progress printer of the mode defined by the flags,
+// where the auto mode is interactive only when the output is a terminal.
+func newProgress(cc *cli.Context) (progressPrinter, error) {
+	mode := cc.String("progress")
+	if !slices.Contains(progressModes, mode) {
+		return nil, usageErrorf(
+			"invalid progress %q, expected one of: %s",
+			mode,
+			strings.Join(progressModes, ", "),
+		)
+	}
+
+	w := cc.App.Writer
+
+	if mode == progressAuto {
+		mode = progressPlain
+		if isTerminal(w) {
+			mode = progressInteractive
+		}
+	}
+
+	if cc.Bool("no-progress") && mode == progressInteractive {
+		mode = progressPlain
+	}
+
+	// NO_COLOR disables colors when set to any value, see https://no-color.org.
+	if cc.Bool("no-color") || os.Getenv("NO_COLOR") != "" || mode != progressInteractive {
+		pterm.DisableColor()
+	}
+
+	switch mode {
+	case progressInteractive:
+		return &regionProgress{w: w}, nil
+	case progressJSON:
+		return &jsonProgress{enc: json.NewEncoder(w)}, nil
+	default:
+		return &lineProgress{w: w}, nil
+	}
+}
+
+// isTerminal reports whether the writer is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// stageText returns the text describing the progress event.
+func stageText(e layer.Event) string {
+	switch e.Stage {
+	case layer.StageWave:
+		return fmt.Sprintf("wave %d: bumping version %d", e.Wave, e.Version)
+	case layer.StageBake:
+		return fmt.Sprintf("wave %d: baking", e.Wave)
+	case layer.StageHealthCheck:
+		return fmt.Sprintf("wave %d: running health check", e.Wave)
+	}
+
+	region := e.Region
+	if e.Account != "" {
+		region = e.Account + "/" + e.Region
+	}
+
+	switch e.Stage {
+	case layer.StageStart:
+		return fmt.Sprintf("%s: starting...", region)
+	case layer.StageDownload:
+		return fmt.Sprintf("%s: downloading version %d", region, e.Version)
+	case layer.StagePublish:
+		return fmt.Sprintf("%s: publishing version %d", region, e.Version)
+	case layer.StageSkip:
+		return fmt.Sprintf("%s: skipping version %d, content already published", region, e.Version)
+	case layer.StageRollback:
+		return fmt.Sprintf("%s: rolling back version %d", region, e.Version)
+	case layer.StageSmokeTest:
+		return fmt.Sprintf("%s: smoke testing version %d", region, e.Version)
+	default:
+		return fmt.Sprintf("%s: bump complete", region)
+	}
+}
+
+// regionProgress renders the progress events of each region with a spinner.
+type regionProgress struct {
+	w        io.Writer
+	multi    *pterm.MultiPrinter
+	spinners map[string]*pterm.SpinnerPrinter
+	writers  map[string]io.Writer
+}
+
+// Printf prints the message.
+func (p *regionProgress) Printf(format string, args ...any) {
+	fmt.Fprintf(p.w, format+"\n", args...)
+}
+
+// Status shows a spinner with the text.
+func (p *regionProgress) Status(text string) func() {
+	spin, err := spinner(p.w, text).Start()
+	if err != nil {
+		return func() {}
+	}
+
+	return func() {
+		_ = spin.Stop()
+	}
+}
+
+// Start starts a spinner for each region.
+func (p *regionProgress) Start(regions []string) error {
+	printer := pterm.DefaultMultiPrinter
+
+	multi, err := printer.WithWriter(p.w).Start()
+	if err != nil {
+		return err
+	}
+
+	p.multi = multi
+	p.spinners = make(map[string]*pterm.SpinnerPrinter, len(regions))
+	p.writers = make(map[string]io.Writer, len(regions))
+
+	for _, region := range regions {
+		w := multi.NewWriter()
+
+		spin, err := spinner(w, fmt.Sprintf("%s: starting...", region)).Start()
+		if err != nil {
+			return err
+		}
+
+		p.spinners[region] = spin
+		p.writers[region] = w
+	}
+
+	return nil
+}
+
+// Update renders the progress event.
+func (p *regionProgress) Update(e layer.Event) {
+	spin, ok := p.spinners[e.Region]
+	if !ok && e.Wave > 0 && p.multi != nil {
+		p.startWave(e)
+		return
+	}
+
+	if !ok {
+		return
+	}
+
+	switch e.Stage {
+	case layer.StageDownload, layer.StagePublish, layer.StageSkip, layer.StageRollback, layer.StageSmokeTest,
+		layer.StageWave, layer.StageBake, layer.StageHealthCheck:
+		spin.UpdateText(stageText(e))
+	case layer.StageComplete:
+		_ = spin.Stop()
+		pterm.Fprint(p.writers[e.Region], stageText(e))
+	}
+}
+
+// startWave starts the spinner of the rollout waves, below the regions.
+func (p *regionProgress) startWave(e layer.Event) {
+	w := p.multi.NewWriter()
+
+	spin, err := spinner(w, stageText(e)).Start()
+	if err != nil {
+		return
+	}
+
+	p.spinners[e.Region] = spin
+	p.writers[e.Region] = w
+}
+
+// Stop stops all spinners.
+func (p *regionProgress) Stop() error {
+	for _, spin := range p.spinners {
+		_ = spin.Stop()
+	}
+
+	if p.multi == nil {
+		return nil
+	}
+
+	_, err := p.multi.Stop()
+	return err
+}
+
+// lineProgress renders the messages and progress events as plain lines.
+type lineProgress struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Printf prints the message in a line.
+func (p *lineProgress) Printf(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprintf(p.w, format+"\n", args...)
+}
+
+// Status prints the text in a line.
+func (p *lineProgress) Status(text string) func() {
+	p.Printf("%s", text)
+	return func() {}
+}
+
+// Start does nothing, the events are printed as they arrive.
+func (p *lineProgress) Start([]string) error {
+	return nil
+}
+
+// Update prints the progress event in a line.
+func (p *lineProgress) Update(e layer.Event) {
+	p.Printf("%s", stageText(e))
+}
+
+// Stop does nothing.
+func (p *lineProgress) Stop() error {
+	return nil
+}
+
+// progressEvent is a line of the NDJSON progress stream.
+type progressEvent struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Message string    `json:"message,omitempty"`
+	Account string    `json:"account,omitempty"`
+	Region  string    `json:"region,omitempty"`
+	Wave    int       `json:"wave,omitempty"`
+	Stage   string    `json:"stage,omitempty"`
+	Version int64     `json:"version,omitempty"`
+	Result  any       `json:"result,omitempty"`
+}
+
+// jsonProgress renders the messages and progress events as a NDJSON stream.
+type jsonProgress struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// emit writes the event as a JSON line.
+func (p *jsonProgress) emit(e progressEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e.Time = time.Now().UTC()
+	_ = p.enc.Encode(e)
+}
+
+// Printf emits the message event, without colors.
+func (p *jsonProgress) Printf(format string, args ...any) {
+	p.emit(progressEvent{Type: "message", Message: pterm.RemoveColorFromString(fmt.Sprintf(format, args...))})
+}
+
+// Status emits the status event.
+func (p *jsonProgress) Status(text string) func() {
+	p.emit(progressEvent{Type: "status", Message: text})
+	return func() {}
+}
+
+// Start does nothing, the events are emitted as they arrive.
+func (p *jsonProgress) Start([]string) error {
+	return nil
+}
+
+// Update emits the progress event.
+func (p *jsonProgress) Update(e layer.Event) {
+	p.emit(progressEvent{
+		Type:    "progress",
+		Account: e.Account,
+		Region:  e.Region,
+		Wave:    e.Wave,
+		Stage:   e.Stage,
+		Version: e.Version,
+	})
+}
+
+// Result emits the result of the command.
+func (p *jsonProgress) Result(v any) {
+	p.emit(progressEvent{Type: "result", Result: v})
+}
+
+// Stop does nothing.
+func (p *jsonProgress) Stop() error {
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+)
+
+// regionFlags are the flags shared by commands that work across regions.
+var regionFlags = []cli.Flag{
+	&cli.StringSliceFlag{
+		Name:    "regions",
+		Aliases: []string{"r"},
+		Usage:   "list of regions or region groups (@name) separated by comma.",
+	},
+	&cli.BoolFlag{
+		Name:  "all-regions",
+		Usage: "use all regions enabled for the account.",
+	},
+	&cli.StringSliceFlag{
+		Name:  "exclude-regions",
+		Usage: "list of regions or region groups (@name) separated by comma to leave out.",
+	},
+}
+
+// resolveRegions returns the regions defined by the flags, discovering the
+// enabled regions of the account when requested.
+func resolveRegions(cc *cli.Context, cfg aws.Config) ([]string, error) {
+	conf, err := loadConfig(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	regions, err := internal.ExpandRegions(cc.StringSlice("regions"), conf.RegionGroups)
+	if err != nil {
+		return nil, usageErrorf("%v", err)
+	}
+
+	excluded, err := internal.ExpandRegions(cc.StringSlice("exclude-regions"), conf.RegionGroups)
+	if err != nil {
+		return nil, usageErrorf("%v", err)
+	}
+
+	if cc.Bool("all-regions") {
+		if len(regions) > 0 {
+			return nil, usageErrorf(`flags "regions" and "all-regions" are mutually exclusive`)
+		}
+
+		all, err := internal.LoadAccount(cfg).Regions(cc.Context)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(all.Disabled) > 0 {
+			pterm.Warning.WithWriter(cc.App.ErrWriter).Printfln(
+				"Skipping regions not enabled for the account: %s",
+				strings.Join(all.Disabled, ", "),
+			)
+		}
+
+		regions = all.Enabled
+	}
+
+	if len(regions) == 0 {
+		return nil, usageErrorf(`required flag "regions" or "all-regions" not set`)
+	}
+
+	regions = internal.Exclude(regions, excluded)
+	if len(regions) <= 1 {
+		return nil, usageErrorf(`required flag "regions" must contain at least two regions`)
+	}
+
+	return regions, nil
+}
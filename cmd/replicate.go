@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+var replicateCmd = &cli.Command{
+	Name:        "replicate",
+	Description: "replicates the layer versions into regions of other accounts",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "source-region",
+			Usage: "region of the source layer, defaults to the aws config region.",
+		},
+		&cli.StringSliceFlag{
+			Name:  "target",
+			Usage: "target account role and region or region group (@name), e.g. arn:aws:iam::111111111111:role/lb=eu-west-1.",
+		},
+		&cli.StringFlag{
+			Name:  "target-external-id",
+			Usage: "external id of the target account roles.",
+		},
+		&cli.BoolFlag{
+			Name:  "no-hooks",
+			Usage: "skips the hooks defined in the config file.",
+		},
+	},
+	ArgsUsage: "layer-name",
+	Action: func(cc *cli.Context) error {
+		name := cc.Args().First()
+		if name == "" {
+			return usageErrorf(`required argument "layer-name" not set`)
+		}
+
+		conf, err := loadConfig(cc)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := internal.ParseTargets(cc.StringSlice("target"), conf.RegionGroups)
+		if err != nil {
+			return usageErrorf("%v", err)
+		}
+
+		if len(accounts) == 0 {
+			return usageErrorf(`required flag "target" not set`)
+		}
+
+		prog, err := newProgress(cc)
+		if err != nil {
+			return err
+		}
+
+		logger, err := newLogger(cc, internal.LogText)
+		if err != nil {
+			return err
+		}
+
+		opts, err := layerOptions(cc)
+		if err != nil {
+			return err
+		}
+
+		cfg, identity, err := loadAWSConfig(cc, logger)
+		if err != nil {
+			return err
+		}
+
+		printIdentity(cc, identity)
+
+		region := cmp.Or(cc.String("source-region"), cfg.Region)
+		if _, err := internal.ExpandRegions([]string{region}, nil); err != nil {
+			return usageErrorf("%v", err)
+		}
+
+		var targets []*layer.Target
+
+		for _, a := range accounts {
+			acfg := internal.AssumeRole(cfg, internal.Credentials{
+				RoleArn:         a.RoleArn,
+				ExternalID:      cc.String("target-external-id"),
+				RoleSessionName: cc.String("role-session-name"),
+			})
+
+			l := layer.New(acfg, name, opts...)
+
+			for _, r := range a.Regions {
+				targets = append(targets, &layer.Target{Account: a.Account, Region: r, Layer: l})
+			}
+		}
+
+		hooks, err := hookOptions(cc, logger)
+		if err != nil {
+			return err
+		}
+
+		audit, err := auditOption(cc, identity)
+		if err != nil {
+			return err
+		}
+
+		b := layer.NewBalancer(cfg, name, slices.Concat(
+			opts,
+			hooks,
+			[]layer.Option{layer.WithProgress(prog.Update), audit},
+		)...)
+
+		stop := prog.Status("getting latest version...")
+		source, err := b.Layer().LatestVersion(cc.Context, region)
+
+		stop()
+
+		if err != nil {
+			return err
+		}
+
+		if source.Number == 0 {
+			return layer.ErrNoVersions
+		}
+
+		prog.Printf("Replicating version %d of region %s", source.Number, source.Region)
+
+		stop = prog.Status("replicating...")
+		results, err := b.Replicate(cc.Context, source, targets)
+
+		stop()
+
+		if jp, ok := prog.(*jsonProgress); ok {
+			for _, r := range results {
+				jp.Result(replicationResult(r))
+			}
+
+			return err
+		}
+
+		if werr := internal.WriteReplications(cc.App.Writer, results); werr != nil {
+			return werr
+		}
+
+		return err
+	},
+}
+
+// replicationResult returns the replication result of the NDJSON progress.
+func replicationResult(r *layer.Replication) map[string]any {
+	result := map[string]any{
+		"account":   r.Account,
+		"region":    r.Region,
+		"previous":  r.Previous,
+		"version":   r.Version(),
+		"published": r.Published,
+	}
+
+	if r.Err != nil {
+		result["error"] = r.Err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/faabiosr/lb/internal"
+)
+
+// reportTarget represents a report format and the file it is written to.
+type reportTarget struct {
+	format string
+	path   string
+}
+
+// parseReports parses the report flag values in the format "format=path".
+func parseReports(values []string) ([]reportTarget, error) {
+	targets := make([]reportTarget, 0, len(values))
+
+	for _, value := range values {
+		format, path, ok := strings.Cut(value, "=")
+		if !ok || path == "" {
+			return nil, usageErrorf(`invalid report %q, expected "format=path"`, value)
+		}
+
+		if !slices.Contains(internal.ReportFormats, format) {
+			return nil, usageErrorf(
+				"invalid report format %q, expected one of: %s",
+				format,
+				strings.Join(internal.ReportFormats, ", "),
+			)
+		}
+
+		targets = append(targets, reportTarget{format: format, path: path})
+	}
+
+	return targets, nil
+}
+
+// writeReports writes the report to each target file.
+func writeReports(targets []reportTarget, r *internal.Report) error {
+	for _, t := range targets {
+		f, err := os.Create(t.path)
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+
+		if err := r.Write(t.format, f); err != nil {
+			_ = f.Close()
+			return err
+		}
+
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write report file: %w", err)
+		}
+	}
+
+	return nil
+}
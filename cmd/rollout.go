@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+// loadRollout loads the rollout defined by the flag from the configuration
+// file, resolving its waves for the regions. It returns nil when no rollout
+// is set.
+func loadRollout(cc *cli.Context, regions []string) (*internal.RolloutConfig, []layer.Wave, error) {
+	name := cc.String("rollout")
+	if name == "" {
+		return nil, nil, nil
+	}
+
+	conf, err := loadConfig(cc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rc, ok := conf.Rollouts[name]
+	if !ok {
+		return nil, nil, usageErrorf("unknown rollout %q", name)
+	}
+
+	waves, err := internal.ResolveWaves(rc, regions, conf.RegionGroups)
+	if err != nil {
+		return nil, nil, usageErrorf("invalid rollout %q: %v", name, err)
+	}
+
+	return &rc, waves, nil
+}
+
+// waveList returns the regions and bake time of each wave.
+func waveList(waves []layer.Wave) string {
+	items := make([]string, len(waves))
+
+	for i, w := range waves {
+		items[i] = fmt.Sprintf("%d) %s", i+1, strings.Join(w.Regions, ", "))
+		if w.BakeTime > 0 && i < len(waves)-1 {
+			items[i] += fmt.Sprintf(" (bake %s)", w.BakeTime)
+		}
+	}
+
+	return strings.Join(items, " ")
+}
+
+// healthCheck returns the health check running the command with the shell,
+// with the layer, version, wave and its regions in the environment. The
+// command output is written to the error writer.
+func healthCheck(cc *cli.Context, command, name string, version int64) layer.HealthCheck {
+	if command == "" {
+		return nil
+	}
+
+	return func(ctx context.Context, n int, w layer.Wave) error {
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Stdout = cc.App.ErrWriter
+		cmd.Stderr = cc.App.ErrWriter
+		cmd.Env = append(
+			os.Environ(),
+			"LB_LAYER="+name,
+			fmt.Sprintf("LB_VERSION=%d", version),
+			fmt.Sprintf("LB_WAVE=%d", n),
+			"LB_REGIONS="+strings.Join(w.Regions, ","),
+		)
+
+		return cmd.Run()
+	}
+}
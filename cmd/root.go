@@ -6,25 +6,34 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 
 	"github.com/pterm/pterm"
 	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
 )
 
 // Execute runs root cmd.
 func Execute(ctx context.Context, args []string) {
 	if err := newCmd().RunContext(ctx, args); err != nil {
-		fmt.Fprintf(os.Stdout, "%v\n\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "%v\n\n", err)
+		os.Exit(exitCode(err))
 	}
 }
 
 const unknown = "unknown"
 
+// layerOptionsKey is the app metadata key holding additional layer options,
+// e.g. the options of a fake lambda client for testing.
+const layerOptionsKey = "layer-options"
+
 // variables are expected to be set at build time.
 var (
 	releaseVersion = unknown
@@ -75,6 +84,57 @@ func newCmd() *cli.App {
 	app.CustomAppHelpTemplate = fmt.Sprintf(appHelpTemplate, helpHeaderTemplate)
 	app.HideHelpCommand = true
 	app.Suggest = true
+	app.OnUsageError = onUsageError
+
+	app.Flags = append([]cli.Flag{
+		&cli.PathFlag{
+			Name:    "config",
+			Usage:   "path of the configuration file.",
+			EnvVars: []string{"LB_CONFIG"},
+			Value:   internal.DefaultConfigPath(),
+		},
+		&cli.PathFlag{
+			Name:    "audit-log",
+			Usage:   "path of the audit journal of the layer mutations.",
+			EnvVars: []string{"LB_AUDIT_LOG"},
+			Value:   internal.DefaultJournalPath(),
+		},
+		&cli.StringSliceFlag{
+			Name:    "endpoint-url",
+			Usage:   "lambda endpoint url of all regions, or of a single region as region=url.",
+			EnvVars: []string{"LB_ENDPOINT_URL"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "download-endpoint-url",
+			Usage:   "endpoint url replacing the host of the layers content location, e.g. of an emulator.",
+			EnvVars: []string{"LB_DOWNLOAD_ENDPOINT_URL"},
+		},
+		&cli.StringFlag{
+			Name:    "progress",
+			Usage:   "progress output: auto, interactive, plain or json, auto is interactive only in a terminal.",
+			EnvVars: []string{"LB_PROGRESS"},
+			Value:   progressAuto,
+		},
+		&cli.BoolFlag{
+			Name:  "no-progress",
+			Usage: "print the progress as plain lines, without spinners.",
+		},
+		&cli.BoolFlag{
+			Name:  "no-color",
+			Usage: "disable colors, also disabled by NO_COLOR.",
+		},
+		&cli.StringFlag{
+			Name:    "log-level",
+			Usage:   "log level: debug, info, warn or error.",
+			EnvVars: []string{"LB_LOG_LEVEL"},
+			Value:   "info",
+		},
+		&cli.StringFlag{
+			Name:    "log-format",
+			Usage:   "log format: text or json, defaults to json for watch and text otherwise.",
+			EnvVars: []string{"LB_LOG_FORMAT"},
+		},
+	}, awsFlags...)
 
 	app.EnableBashCompletion = true
 	app.BashComplete = func(ctx *cli.Context) {
@@ -90,7 +150,7 @@ func newCmd() *cli.App {
 		return nil
 	}
 
-	app.Commands = commands(bumpCmd, verifyCmd)
+	app.Commands = commands(bumpCmd, verifyCmd, layersCmd, watchCmd, replicateCmd, mirrorCmd, auditCmd, completionCmd, completeCmd)
 
 	return app
 }
@@ -101,11 +161,56 @@ func commands(cmds ...*cli.Command) []*cli.Command {
 		cmd.Usage = cmd.Description
 		cmd.HideHelpCommand = true
 		cmd.CustomHelpTemplate = commandHelpTemplate
+		cmd.OnUsageError = onUsageError
 	}
 
 	return cmds
 }
 
+// layerOptions returns the layer options defined by the flags, followed by
+// the additional layer options of the app.
+func layerOptions(cc *cli.Context) ([]layer.Option, error) {
+	return endpointOptions(cc, cc.StringSlice("endpoint-url"), cc.StringSlice("download-endpoint-url"))
+}
+
+// endpointOptions returns the layer options of the endpoint flag values,
+// followed by the additional layer options of the app.
+func endpointOptions(cc *cli.Context, endpointURLs, downloadURLs []string) ([]layer.Option, error) {
+	endpoints, err := internal.ParseEndpoints(endpointURLs)
+	if err != nil {
+		return nil, usageErrorf("%v", err)
+	}
+
+	downloads, err := internal.ParseEndpoints(downloadURLs)
+	if err != nil {
+		return nil, usageErrorf("%v", err)
+	}
+
+	var opts []layer.Option
+	if len(endpoints) > 0 {
+		opts = append(opts, layer.WithEndpoints(endpoints))
+	}
+
+	if len(downloads) > 0 {
+		opts = append(opts, layer.WithDownloadEndpoints(downloads))
+	}
+
+	extra, _ := cc.App.Metadata[layerOptionsKey].([]layer.Option)
+
+	return append(opts, extra...), nil
+}
+
+// newLogger creates the logger writing to stderr, separated from the command
+// result, using the format when the log format is not set.
+func newLogger(cc *cli.Context, format string) (*slog.Logger, error) {
+	logger, err := internal.NewLogger(cc.App.ErrWriter, cc.String("log-level"), cmp.Or(cc.String("log-format"), format))
+	if err != nil {
+		return nil, usageErrorf("%v", err)
+	}
+
+	return logger, nil
+}
+
 func spinner(w io.Writer, text string) *pterm.SpinnerPrinter {
 	return pterm.DefaultSpinner.
 		WithSequence([]string{"⣾ ", "⣽ ", "⣻ ", "⢿ ", "⡿ ", "⣟ ", "⣯ ", "⣷ "}...).
@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+// smokeTestOptions returns the option running the smoke test of the layer
+// defined in the configuration file, if any.
+func smokeTestOptions(cc *cli.Context, cfg aws.Config, name string) ([]layer.Option, error) {
+	if cc.Bool("no-smoke-test") {
+		return nil, nil
+	}
+
+	conf, err := loadConfig(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	st, ok := conf.SmokeTests[name]
+	if !ok {
+		return nil, nil
+	}
+
+	endpoints, err := internal.ParseEndpoints(cc.StringSlice("endpoint-url"))
+	if err != nil {
+		return nil, usageErrorf("%v", err)
+	}
+
+	tester, err := internal.NewSmokeTester(st, lambda.NewFromConfig(cfg), endpoints)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smoke test of layer %s: %w", name, err)
+	}
+
+	return []layer.Option{layer.WithSmokeTest(tester.Test), layer.WithSmokeRestore(tester.Restore)}, nil
+}
@@ -7,73 +7,99 @@ package cmd
 
 import (
 	"errors"
-	"fmt"
 	"slices"
 
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/urfave/cli/v2"
 
 	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
 )
 
 var verifyCmd = &cli.Command{
 	Name:        "verify",
 	Description: "verifies layer latest versions across regions",
-	Flags: []cli.Flag{
+	Flags: slices.Concat(regionFlags, []cli.Flag{
 		&cli.StringSliceFlag{
-			Name:     "regions",
-			Aliases:  []string{"r"},
-			Usage:    "list of regions separated by comma.",
-			Required: true,
+			Name:  "report",
+			Usage: "writes a report in the format to the file, e.g. junit=out.xml or markdown=out.md.",
 		},
-	},
+	}),
 	ArgsUsage: "layer-name",
 	Action: func(cc *cli.Context) error {
 		name := cc.Args().First()
 		if name == "" {
-			return errors.New(`required argument "layer-name" not set`)
+			return usageErrorf(`required argument "layer-name" not set`)
 		}
 
-		regions := cc.StringSlice("regions")
-		if len(regions) <= 1 {
-			return errors.New(`required flag "regions" must contain at least two regions`)
+		reports, err := parseReports(cc.StringSlice("report"))
+		if err != nil {
+			return err
 		}
 
-		cfg, err := config.LoadDefaultConfig(cc.Context)
+		prog, err := newProgress(cc)
 		if err != nil {
-			return fmt.Errorf("failed to load aws config: %w", err)
+			return err
 		}
 
-		l := internal.LoadLayer(cfg, name)
+		logger, err := newLogger(cc, internal.LogText)
+		if err != nil {
+			return err
+		}
+
+		notifier, err := newNotifier(cc, logger)
+		if err != nil {
+			return err
+		}
 
-		spin, err := spinner(cc.App.Writer, "verifying...").Start()
+		opts, err := layerOptions(cc)
 		if err != nil {
 			return err
 		}
 
-		versions, err := l.LatestVersions(cc.Context, regions)
+		cfg, identity, err := loadAWSConfig(cc, logger)
 		if err != nil {
-			_ = spin.Stop()
 			return err
 		}
 
-		bumped := slices.CompactFunc(versions, func(c, v *internal.Version) bool {
-			return c.Number == v.Number
-		})
+		printIdentity(cc, identity)
 
-		if len(bumped) == 1 && bumped[0].Number == 0 {
-			_ = spin.Stop()
-			return errors.New("there are no published versions")
+		regions, err := resolveRegions(cc, cfg)
+		if err != nil {
+			return err
 		}
 
-		if len(bumped) > 1 {
-			_ = spin.Stop()
-			return errors.New(`some regions are not bumped`)
+		b := layer.NewBalancer(cfg, name, opts...)
+
+		stop := prog.Status("verifying...")
+		versions, err := b.Verify(cc.Context, regions)
+
+		stop()
+
+		notifier.send(cc.Context, internal.NewNotification(verifyEvent(err), name, versions, err))
+
+		if versions != nil {
+			if err := writeReports(reports, internal.NewReport(name, versions)); err != nil {
+				return err
+			}
 		}
 
-		_ = spin.Stop()
+		if err != nil {
+			return err
+		}
 
-		fmt.Fprintln(cc.App.Writer, "all regions bumped")
+		prog.Printf("all regions bumped")
 		return nil
 	},
 }
+
+// verifyEvent returns the notification event of the verification result.
+func verifyEvent(err error) string {
+	switch {
+	case errors.Is(err, layer.ErrDrift):
+		return internal.EventDriftDetected
+	case err != nil:
+		return internal.EventVerifyFailed
+	default:
+		return internal.EventVerifyPassed
+	}
+}
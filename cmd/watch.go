@@ -0,0 +1,189 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/lb/internal"
+	"github.com/faabiosr/lb/layer"
+)
+
+var watchCmd = &cli.Command{
+	Name:        "watch",
+	Description: "continuously verifies layers across regions, bumping them on drift",
+	Flags: slices.Concat(regionFlags, []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "interval",
+			Usage: "interval between reconcile cycles.",
+			Value: 5 * time.Minute,
+		},
+		&cli.Float64Flag{
+			Name:  "jitter",
+			Usage: "randomizes the interval by up to this factor, e.g. 0.1 for 10%.",
+			Value: 0.1,
+		},
+		&cli.BoolFlag{
+			Name:  "bump",
+			Usage: "bumps the layer when drift is detected.",
+		},
+		&cli.DurationFlag{
+			Name:  "shutdown-timeout",
+			Usage: "time the reconcile cycle in progress has to finish after SIGINT or SIGTERM.",
+			Value: 30 * time.Second,
+		},
+		&cli.StringFlag{
+			Name:  "metrics-addr",
+			Usage: "address to serve prometheus metrics on /metrics, e.g. :9090.",
+		},
+	}),
+	ArgsUsage: "[layer-name...]",
+	Action: func(cc *cli.Context) error {
+		if cc.Duration("interval") <= 0 {
+			return usageErrorf(`flag "interval" must be greater than zero`)
+		}
+
+		if j := cc.Float64("jitter"); j < 0 || j >= 1 {
+			return usageErrorf(`flag "jitter" must be between 0 and 1`)
+		}
+
+		logger, err := newLogger(cc, internal.LogJSON)
+		if err != nil {
+			return err
+		}
+
+		opts, err := layerOptions(cc)
+		if err != nil {
+			return err
+		}
+
+		cfg, identity, err := loadAWSConfig(cc, logger)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("caller identity", "arn", identity.Arn, "account", identity.Account)
+
+		audit, err := auditOption(cc, identity)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, audit)
+
+		if addr := cc.String("metrics-addr"); addr != "" {
+			reg := prometheus.NewRegistry()
+			opts = append(opts, internal.NewMetrics(reg).Options()...)
+
+			srv := metricsServer(addr, reg)
+			defer srv.Close() // nolint:errcheck
+
+			go func() {
+				if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Error("metrics server failed", "error", err)
+				}
+			}()
+		}
+
+		targets, err := watchTargets(cc, cfg, opts...)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cc.Context, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		w := &internal.Watcher{
+			Targets:  targets,
+			Interval: cc.Duration("interval"),
+			Jitter:   cc.Float64("jitter"),
+			Bump:     cc.Bool("bump"),
+			Logger:   logger,
+
+			ShutdownTimeout: cc.Duration("shutdown-timeout"),
+		}
+
+		return w.Run(ctx)
+	},
+}
+
+// watchTargets returns the layers from the arguments, balanced across the
+// regions defined by the flags, or the layers defined in the config file.
+func watchTargets(cc *cli.Context, cfg aws.Config, opts ...layer.Option) ([]internal.Target, error) {
+	var targets []internal.Target
+
+	if cc.Args().Present() {
+		regions, err := resolveRegions(cc, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range cc.Args().Slice() {
+			targets = append(targets, internal.Target{
+				Balancer: layer.NewBalancer(cfg, name, opts...),
+				Regions:  regions,
+			})
+		}
+
+		return targets, nil
+	}
+
+	conf, err := loadConfig(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lc := range conf.Layers {
+		regions, err := internal.ExpandRegions(lc.Regions, conf.RegionGroups)
+		if err != nil {
+			return nil, usageErrorf("layer %s: %v", lc.Name, err)
+		}
+
+		if len(regions) <= 1 {
+			return nil, usageErrorf("layer %s: must contain at least two regions", lc.Name)
+		}
+
+		targets = append(targets, internal.Target{
+			Balancer: layer.NewBalancer(cfg, lc.Name, opts...),
+			Regions:  regions,
+		})
+	}
+
+	if len(targets) == 0 {
+		return nil, usageErrorf(`required argument "layer-name" not set and no layers configured`)
+	}
+
+	return targets, nil
+}
+
+// metricsServer creates the http server exposing the metrics of the registry.
+func metricsServer(addr string, reg *prometheus.Registry) *http.Server {
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// AuditVersion is a layer version recorded by the audit journal.
+type AuditVersion struct {
+	Region     string `json:"region"`
+	Version    int64  `json:"version"`
+	Arn        string `json:"arn,omitempty"`
+	CodeSha256 string `json:"code_sha256,omitempty"`
+}
+
+// AuditEntry is a layer mutation recorded by the audit journal.
+type AuditEntry struct {
+	Time     time.Time     `json:"time"`
+	Started  time.Time     `json:"started"`
+	Finished time.Time     `json:"finished"`
+	Identity *Identity     `json:"identity"`
+	Command  string        `json:"command"`
+	Action   string        `json:"action"`
+	Layer    string        `json:"layer"`
+	Account  string        `json:"account,omitempty"`
+	Source   *AuditVersion `json:"source,omitempty"`
+	Target   *AuditVersion `json:"target"`
+}
+
+// AuditFilter selects the audit entries, the zero value selects all entries.
+type AuditFilter struct {
+	Layer  string
+	Region string
+	Action string
+	Since  time.Time
+}
+
+// Match reports whether the entry is selected by the filter.
+func (f AuditFilter) Match(e *AuditEntry) bool {
+	return (f.Layer == "" || f.Layer == e.Layer) &&
+		(f.Region == "" || f.Region == e.Target.Region) &&
+		(f.Action == "" || f.Action == e.Action) &&
+		(f.Since.IsZero() || !e.Time.Before(f.Since))
+}
+
+// DefaultJournalPath returns the default path of the audit journal.
+func DefaultJournalPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "lb", "audit.jsonl")
+}
+
+// Journal is the append-only audit journal of the layer mutations, stored as
+// JSON lines.
+type Journal struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewJournal creates the journal stored in the path.
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// Check verifies the journal can be appended, creating it when missing, so
+// the layer mutations are not made when they cannot be recorded.
+func (j *Journal) Check() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := j.open()
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// Append appends the entry to the journal, creating it when missing.
+func (j *Journal) Append(e *AuditEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := j.open()
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(f).Encode(e); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write audit journal: %w", err)
+	}
+
+	return f.Close()
+}
+
+// open opens the journal for appending, creating it when missing.
+func (j *Journal) open() (*os.File, error) {
+	if j.path == "" {
+		return nil, errors.New("audit journal path not set")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit journal: %w", err)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit journal: %w", err)
+	}
+
+	return f, nil
+}
+
+// Entries returns the entries selected by the filter, in the recorded order.
+// A missing journal has no entries.
+func (j *Journal) Entries(filter AuditFilter) ([]*AuditEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.Open(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit journal: %w", err)
+	}
+	defer f.Close() // nolint:errcheck
+
+	var entries []*AuditEntry
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		e := &AuditEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), e); err != nil {
+			return nil, fmt.Errorf("invalid audit journal entry at line %d: %w", line, err)
+		}
+
+		if e.Target != nil && filter.Match(e) {
+			entries = append(entries, e)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit journal: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Recorder returns the recorder appending the mutations of the command,
+// made with the caller identity.
+func (j *Journal) Recorder(command string, identity *Identity) layer.Recorder {
+	return &journalRecorder{journal: j, command: command, identity: identity}
+}
+
+// journalRecorder records the layer mutations into the journal.
+type journalRecorder struct {
+	journal  *Journal
+	command  string
+	identity *Identity
+}
+
+// Record appends the mutation to the journal.
+func (r *journalRecorder) Record(m layer.Mutation) error {
+	return r.journal.Append(&AuditEntry{
+		Time:     time.Now().UTC(),
+		Started:  m.Started.UTC(),
+		Finished: m.Finished.UTC(),
+		Identity: r.identity,
+		Command:  r.command,
+		Action:   m.Action,
+		Layer:    m.Layer,
+		Account:  m.Account,
+		Source:   auditVersion(m.Source),
+		Target:   auditVersion(m.Target),
+	})
+}
+
+// auditVersion returns the audit version of the layer version.
+func auditVersion(v *layer.Version) *AuditVersion {
+	if v == nil {
+		return nil
+	}
+
+	return &AuditVersion{
+		Region:     v.Region,
+		Version:    v.Number,
+		Arn:        v.Arn,
+		CodeSha256: v.CodeSha256,
+	}
+}
+
+// WriteAudit writes the entries as a table.
+func WriteAudit(w io.Writer, entries []*AuditEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "TIME\tACTION\tLAYER\tTARGET\tSOURCE\tCODE SHA256\tIDENTITY")
+
+	for _, e := range entries {
+		target := fmt.Sprintf("%s:%d", e.Target.Region, e.Target.Version)
+		if e.Account != "" {
+			target = e.Account + "/" + target
+		}
+
+		source := "-"
+		if e.Source != nil {
+			source = fmt.Sprintf("%s:%d", e.Source.Region, e.Source.Version)
+		}
+
+		identity := "-"
+		if e.Identity != nil {
+			identity = e.Identity.Arn
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Time.Format(time.RFC3339),
+			e.Action,
+			e.Layer,
+			target,
+			source,
+			e.Target.CodeSha256,
+			identity,
+		)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+func TestJournalCheck(t *testing.T) {
+	dir := t.TempDir()
+
+	// a file as parent directory makes the journal not writable.
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		err  string
+	}{
+		{
+			name: "writable",
+			path: filepath.Join(dir, "lb", "audit.jsonl"),
+		},
+		{
+			name: "path not set",
+			err:  "audit journal path not set",
+		},
+		{
+			name: "not writable",
+			path: filepath.Join(file, "audit.jsonl"),
+			err:  "failed to create audit journal:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := NewJournal(tt.path).Check()
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if !strings.HasPrefix(err, tt.err) || (tt.err == "") != (err == "") {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lb", "audit.jsonl")
+	j := NewJournal(path)
+
+	entries, err := j.Entries(AuditFilter{})
+	if err != nil || entries != nil {
+		t.Fatalf("expected no entries for a missing journal, got %v (%v)", entries, err)
+	}
+
+	identity := &Identity{Account: "123456789012", Arn: "arn:aws:iam::123456789012:user/lb"}
+	rec := j.Recorder("bump", identity)
+
+	for _, region := range []string{"eu-west-1", "sa-east-1"} {
+		err := rec.Record(layer.Mutation{
+			Action:   layer.ActionPublish,
+			Layer:    "my-layer",
+			Source:   &layer.Version{Region: "us-east-1", Number: 3, CodeSha256: "sha"},
+			Target:   &layer.Version{Region: region, Number: 2, CodeSha256: "sha"},
+			Started:  time.Now(),
+			Finished: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("expected nil, got error %v", err)
+		}
+	}
+
+	entries, err = j.Entries(AuditFilter{Layer: "my-layer", Region: "sa-east-1", Action: layer.ActionPublish})
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("expected entries '1', got '%d'", len(entries))
+	}
+
+	e := entries[0]
+	if e.Command != "bump" || e.Identity.Arn != identity.Arn || e.Source.Version != 3 || e.Target.CodeSha256 != "sha" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	entries, _ = j.Entries(AuditFilter{Since: time.Now().Add(time.Hour)})
+	if len(entries) != 0 {
+		t.Errorf("expected no entries in the future, got '%d'", len(entries))
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteAudit(buf, []*AuditEntry{e}); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "publish  my-layer  sa-east-1:2  us-east-1:3  sha") {
+		t.Errorf("unexpected table:\n%s", buf)
+	}
+
+	if err := os.WriteFile(path, []byte("{invalid\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = j.Entries(AuditFilter{})
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid audit journal entry at line 1") {
+		t.Errorf("expected an invalid entry error, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCompletionCachePath returns the default location of the cache of the
+// shell completion.
+func DefaultCompletionCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "lb", "completion.json")
+}
+
+// cacheEntry represents the cached names of a key.
+type cacheEntry struct {
+	Names   []string  `json:"names"`
+	Updated time.Time `json:"updated"`
+}
+
+// NameCache caches names by key in a file for the duration of the TTL, e.g.
+// the layer names of the shell completion, so they are not listed on every
+// completion.
+type NameCache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewNameCache creates the cache stored in the file of the path.
+func NewNameCache(path string, ttl time.Duration) *NameCache {
+	return &NameCache{path: path, ttl: ttl, now: time.Now}
+}
+
+// Names returns the cached names of the key, fetching and caching them when
+// missing or expired. The expired names are returned when fetching fails.
+func (c *NameCache) Names(ctx context.Context, key string, fetch func(context.Context) ([]string, error)) ([]string, error) {
+	entries, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := entries[key]
+	if ok && c.now().Sub(entry.Updated) < c.ttl {
+		return entry.Names, nil
+	}
+
+	names, err := fetch(ctx)
+	if err != nil {
+		if ok {
+			return entry.Names, nil
+		}
+
+		return nil, err
+	}
+
+	entries[key] = cacheEntry{Names: names, Updated: c.now()}
+
+	return names, c.write(entries)
+}
+
+// read reads the cached entries, a missing file results in no entries.
+func (c *NameCache) read() (map[string]cacheEntry, error) {
+	entries := map[string]cacheEntry{}
+
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return entries, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read cache: %w", err)
+	}
+
+	// a corrupted cache is discarded.
+	_ = json.Unmarshal(data, &entries)
+
+	return entries, nil
+}
+
+// write replaces the cache file with the entries.
+func (c *NameCache) write(entries map[string]cacheEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("unable to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".completion-*")
+	if err != nil {
+		return fmt.Errorf("unable to write cache: %w", err)
+	}
+
+	defer os.Remove(tmp.Name()) // nolint:errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("unable to write cache: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("unable to write cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNameCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lb", "completion.json")
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	c := NewNameCache(path, time.Minute)
+	c.now = func() time.Time { return now }
+
+	calls := 0
+	fetch := func(names ...string) func(context.Context) ([]string, error) {
+		return func(context.Context) ([]string, error) {
+			calls++
+
+			if len(names) == 0 {
+				return nil, errors.New("failure")
+			}
+
+			return names, nil
+		}
+	}
+
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		fetch    func(context.Context) ([]string, error)
+		expected []string
+		calls    int
+		err      string
+	}{
+		{
+			name:  "missing and fetch failure",
+			fetch: fetch(),
+			calls: 1,
+			err:   "failure",
+		},
+		{
+			name:     "missing",
+			fetch:    fetch("a-layer", "b-layer"),
+			expected: []string{"a-layer", "b-layer"},
+			calls:    2,
+		},
+		{
+			name:     "cached",
+			elapsed:  30 * time.Second,
+			fetch:    fetch("c-layer"),
+			expected: []string{"a-layer", "b-layer"},
+			calls:    2,
+		},
+		{
+			name:     "expired and fetch failure",
+			elapsed:  time.Minute,
+			fetch:    fetch(),
+			expected: []string{"a-layer", "b-layer"},
+			calls:    3,
+		},
+		{
+			name:     "expired",
+			elapsed:  time.Minute,
+			fetch:    fetch("c-layer"),
+			expected: []string{"c-layer"},
+			calls:    4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.elapsed)
+
+			// a new cache reads the entries of the file.
+			c = NewNameCache(path, time.Minute)
+			c.now = func() time.Time { return now }
+
+			names, err := c.Names(context.Background(), "default|us-east-1", tt.fetch)
+
+			if err != nil && err.Error() != tt.err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if err == nil && tt.err != "" {
+				t.Errorf("expected error '%s', got nil", tt.err)
+			}
+
+			if !slices.Equal(names, tt.expected) {
+				t.Errorf("expected names %v, got %v", tt.expected, names)
+			}
+
+			if calls != tt.calls {
+				t.Errorf("expected fetch calls '%d', got '%d'", tt.calls, calls)
+			}
+		})
+	}
+}
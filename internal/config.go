@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// regionGroupEnvPrefix is the prefix of environment variables defining region groups,
+// e.g. LB_REGION_GROUP_PROD_PRIMARY=us-east-1,eu-west-1 defines the group "prod-primary".
+const regionGroupEnvPrefix = "LB_REGION_GROUP_"
+
+// Config represents the lb configuration file.
+type Config struct {
+	RegionGroups  map[string][]string        `json:"region_groups"`
+	Layers        []LayerConfig              `json:"layers"`
+	Notifications []NotificationConfig       `json:"notifications"`
+	Rollouts      map[string]RolloutConfig   `json:"rollouts"`
+	SmokeTests    map[string]SmokeTestConfig `json:"smoke_tests"`
+	Hooks         map[string][]string        `json:"hooks"`
+}
+
+// LayerConfig represents a layer and the regions (or region groups) it is balanced across.
+type LayerConfig struct {
+	Name    string   `json:"name"`
+	Regions []string `json:"regions"`
+}
+
+// DefaultConfigPath returns the default location of the configuration file.
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "lb", "config.json")
+}
+
+// LoadConfig reads the configuration file, a missing file results in an empty
+// configuration. Region groups defined in the environment take precedence over
+// the ones defined in the file.
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("unable to read config file: %w", err)
+		}
+
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("unable to parse config file %s: %w", path, err)
+			}
+		}
+	}
+
+	if cfg.RegionGroups == nil {
+		cfg.RegionGroups = map[string][]string{}
+	}
+
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+
+		name, ok := strings.CutPrefix(key, regionGroupEnvPrefix)
+		if !ok || name == "" {
+			continue
+		}
+
+		name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+		cfg.RegionGroups[name] = splitList(value)
+	}
+
+	return cfg, nil
+}
+
+// splitList splits a comma separated list, ignoring empty items.
+func splitList(value string) []string {
+	var items []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
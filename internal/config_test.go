@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		cfg, err := LoadConfig(filepath.Join(t.TempDir(), "config.json"))
+		if err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		if total := len(cfg.RegionGroups); total != 0 {
+			t.Errorf("expected region groups '0', got '%d'", total)
+		}
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := LoadConfig(path)
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("region groups from file and environment", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		content := `{"region_groups": {"eu": ["eu-west-1"], "us": ["us-east-1", "us-west-2"]}}`
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Setenv("LB_REGION_GROUP_EU", "eu-central-1, eu-west-1")
+		t.Setenv("LB_REGION_GROUP_PROD_PRIMARY", "us-east-1,eu-central-1")
+
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		expected := map[string][]string{
+			"eu":           {"eu-central-1", "eu-west-1"},
+			"us":           {"us-east-1", "us-west-2"},
+			"prod-primary": {"us-east-1", "eu-central-1"},
+		}
+
+		for name, regions := range expected {
+			if got := cfg.RegionGroups[name]; !slices.Equal(got, regions) {
+				t.Errorf("expected group %s regions %v, got %v", name, regions, got)
+			}
+		}
+	})
+}
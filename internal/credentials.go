@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// defaultSessionName is the session name of the assumed roles.
+const defaultSessionName = "lb"
+
+// defaultSTSRegion is the region of the sts requests when neither the aws
+// config nor the credentials define one.
+const defaultSTSRegion = "us-east-1"
+
+// Credentials defines how the aws credentials are resolved, the zero value
+// uses the default credential chain.
+type Credentials struct {
+	// Profile is the shared config profile.
+	Profile string
+
+	// RoleArn is the role assumed on top of the resolved credentials.
+	RoleArn string
+
+	// ExternalID is the external id of the assumed role.
+	ExternalID string
+
+	// RoleSessionName is the session name of the assumed role.
+	RoleSessionName string
+
+	// MFASerial is the MFA device of the assumed role.
+	MFASerial string
+
+	// STSRegion is the region of the sts requests when the aws config has
+	// none, defaulting to us-east-1.
+	STSRegion string
+
+	// TokenProvider provides the MFA token code, prompting on stdin by default.
+	TokenProvider func() (string, error)
+}
+
+// LoadAWSConfig loads the aws config resolving the credentials, so every
+// client created from it shares the same identity.
+func LoadAWSConfig(ctx context.Context, c Credentials) (aws.Config, error) {
+	tokenProvider := c.TokenProvider
+	if tokenProvider == nil {
+		tokenProvider = stscreds.StdinTokenProvider
+	}
+
+	// the token provider also applies to roles with mfa_serial set in the profile.
+	opts := []func(*config.LoadOptions) error{
+		config.WithAssumeRoleCredentialOptions(func(o *stscreds.AssumeRoleOptions) {
+			o.TokenProvider = tokenProvider
+		}),
+	}
+
+	if c.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(c.Profile))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	if c.RoleArn == "" {
+		return cfg, nil
+	}
+
+	c.TokenProvider = tokenProvider
+
+	return AssumeRole(cfg, c), nil
+}
+
+// AssumeRole returns a copy of the aws config with the credentials of the
+// role, assumed with the credentials of the config.
+func AssumeRole(cfg aws.Config, c Credentials) aws.Config {
+	provider := stscreds.NewAssumeRoleProvider(NewSTSClient(cfg, c.STSRegion), c.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = defaultSessionName
+		if c.RoleSessionName != "" {
+			o.RoleSessionName = c.RoleSessionName
+		}
+
+		if c.ExternalID != "" {
+			o.ExternalID = aws.String(c.ExternalID)
+		}
+
+		if c.MFASerial != "" {
+			o.SerialNumber = aws.String(c.MFASerial)
+			o.TokenProvider = c.TokenProvider
+		}
+	})
+
+	cfg = cfg.Copy()
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+
+	return cfg
+}
+
+// NewSTSClient creates the sts client of the aws config, sending the requests
+// to the region when the config has none.
+func NewSTSClient(cfg aws.Config, region string) *sts.Client {
+	return sts.NewFromConfig(cfg, func(o *sts.Options) {
+		if o.Region == "" {
+			o.Region = cmp.Or(region, defaultSTSRegion)
+		}
+	})
+}
+
+// IdentityClient is the subset of the sts API used to resolve the caller identity.
+type IdentityClient interface {
+	GetCallerIdentity(context.Context, *sts.GetCallerIdentityInput, ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// Identity is the identity of the resolved credentials.
+type Identity struct {
+	Account string `json:"account"`
+	Arn     string `json:"arn"`
+}
+
+// String returns the identity arn with its account.
+func (i *Identity) String() string {
+	return fmt.Sprintf("%s (account %s)", i.Arn, i.Account)
+}
+
+// CallerIdentity resolves the identity of the credentials.
+func CallerIdentity(ctx context.Context, svc IdentityClient) (*Identity, error) {
+	out, err := svc.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve the caller identity: %w", err)
+	}
+
+	return &Identity{
+		Account: aws.ToString(out.Account),
+		Arn:     aws.ToString(out.Arn),
+	}, nil
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestLoadAWSConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+
+	profiles := "[profile ci]\nregion = eu-west-1\n"
+	if err := os.WriteFile(path, []byte(profiles), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("AWS_CONFIG_FILE", path)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_PROFILE", "")
+
+	tests := []struct {
+		name   string
+		creds  Credentials
+		region string
+		role   bool
+		err    string
+	}{
+		{
+			name:  "unknown profile",
+			creds: Credentials{Profile: "unknown"},
+			err:   "failed to load aws config: failed to get shared config profile, unknown",
+		},
+		{
+			name:   "profile",
+			creds:  Credentials{Profile: "ci"},
+			region: "eu-west-1",
+		},
+		{
+			name:   "assume role",
+			creds:  Credentials{Profile: "ci", RoleArn: "arn:aws:iam::123456789012:role/lb", MFASerial: "arn:aws:iam::123456789012:mfa/lb"},
+			region: "eu-west-1",
+			role:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, actual := LoadAWSConfig(context.Background(), tt.creds)
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if tt.err != err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if cfg.Region != tt.region {
+				t.Errorf("expected region '%s', got '%s'", tt.region, cfg.Region)
+			}
+
+			if _, ok := cfg.Credentials.(*aws.CredentialsCache); tt.role && !ok {
+				t.Errorf("expected assumed role credentials, got %T", cfg.Credentials)
+			}
+		})
+	}
+}
+
+func TestNewSTSClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      aws.Config
+		region   string
+		expected string
+	}{
+		{name: "config region", cfg: aws.Config{Region: "eu-west-1"}, region: "sa-east-1", expected: "eu-west-1"},
+		{name: "region", region: "sa-east-1", expected: "sa-east-1"},
+		{name: "default region", expected: "us-east-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewSTSClient(tt.cfg, tt.region).Options().Region; got != tt.expected {
+				t.Errorf("expected region '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCallerIdentity(t *testing.T) {
+	t.Run("response failure", func(t *testing.T) {
+		_, err := CallerIdentity(context.Background(), &mockIdentitySvc{err: errors.New("failure")})
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("identity", func(t *testing.T) {
+		got, err := CallerIdentity(context.Background(), &mockIdentitySvc{})
+		if err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		expected := "arn:aws:iam::123456789012:user/lb (account 123456789012)"
+		if got.String() != expected {
+			t.Errorf("expected identity '%s', got '%s'", expected, got)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ParseEndpoints parses the endpoint URLs, either "url" for all regions or
+// "region=url" for a single region, returning them by region where the empty
+// region holds the endpoint of all regions.
+func ParseEndpoints(values []string) (map[string]string, error) {
+	endpoints := map[string]string{}
+
+	for _, value := range splitList(strings.Join(values, ",")) {
+		region, endpoint, ok := strings.Cut(value, "=")
+		if !ok {
+			region, endpoint = "", value
+		}
+
+		if region != "" && !slices.Contains(KnownRegions, region) {
+			return nil, unknownError("region", region, KnownRegions)
+		}
+
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid endpoint url %q, expected an http or https url", endpoint)
+		}
+
+		if _, ok := endpoints[region]; ok {
+			return nil, fmt.Errorf("endpoint url of %q defined more than once", cmp.Or(region, "all regions"))
+		}
+
+		endpoints[region] = endpoint
+	}
+
+	return endpoints, nil
+}
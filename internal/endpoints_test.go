@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name:     "no endpoints",
+			expected: map[string]string{},
+		},
+		{
+			name:   "all and single region",
+			values: []string{"http://localhost:4566", "eu-west-1=https://vpce.eu-west-1.example.com"},
+			expected: map[string]string{
+				"":          "http://localhost:4566",
+				"eu-west-1": "https://vpce.eu-west-1.example.com",
+			},
+		},
+		{
+			name:   "unknown region",
+			values: []string{"eu-wst-1=http://localhost:4566"},
+			err:    `unknown region "eu-wst-1". Did you mean "eu-west-1"?`,
+		},
+		{
+			name:   "invalid url",
+			values: []string{"localhost:4566"},
+			err:    `invalid endpoint url "localhost:4566", expected an http or https url`,
+		},
+		{
+			name:   "duplicated region",
+			values: []string{"eu-west-1=http://localhost:4566", "eu-west-1=http://localhost:4567"},
+			err:    `endpoint url of "eu-west-1" defined more than once`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, actual := ParseEndpoints(tt.values)
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if tt.err != err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if tt.err == "" && !maps.Equal(got, tt.expected) {
+				t.Errorf("expected endpoints %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"errors"
+	"net"
+	"slices"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/smithy-go"
+)
+
+// authErrorCodes are the AWS error codes related to authentication and authorization.
+var authErrorCodes = []string{
+	"AccessDenied",
+	"AccessDeniedException",
+	"ExpiredToken",
+	"ExpiredTokenException",
+	"IncompleteSignature",
+	"InvalidClientTokenId",
+	"InvalidSignatureException",
+	"MissingAuthenticationToken",
+	"SignatureDoesNotMatch",
+	"UnauthorizedOperation",
+	"UnrecognizedClientException",
+}
+
+// IsAuthError reports whether the error was caused by missing, invalid or
+// expired credentials, or by denied access.
+func IsAuthError(err error) bool {
+	var signErr *v4.SigningError
+	if errors.As(err, &signErr) {
+		return true
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return slices.Contains(authErrorCodes, apiErr.ErrorCode())
+	}
+
+	return false
+}
+
+// IsNetworkError reports whether the error was caused by the network.
+func IsNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
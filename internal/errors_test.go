@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/smithy-go"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+func TestIsAuthError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "signing failure",
+			err:      fmt.Errorf("wrapped: %w", &v4.SigningError{Err: errors.New("no credentials")}),
+			expected: true,
+		},
+		{
+			name:     "access denied",
+			err:      &layer.RegionError{Region: "us-east-1", Err: &smithy.GenericAPIError{Code: "AccessDeniedException"}},
+			expected: true,
+		},
+		{
+			name: "other api error",
+			err:  &smithy.GenericAPIError{Code: "ResourceNotFoundException"},
+		},
+		{
+			name: "generic error",
+			err:  errors.New("failure"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAuthError(tt.err); got != tt.expected {
+				t.Errorf("expected '%t', got '%t'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsNetworkError(t *testing.T) {
+	if !IsNetworkError(fmt.Errorf("wrapped: %w", &net.DNSError{Err: "no such host"})) {
+		t.Error("expected a network error")
+	}
+
+	if IsNetworkError(errors.New("failure")) {
+		t.Error("expected not a network error")
+	}
+}
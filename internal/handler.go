@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// actions supported by the lambda function handler.
+const (
+	ActionVerify = "verify"
+	ActionBump   = "bump"
+)
+
+// Invocation is the event payload handled by the lambda function, e.g.
+// {"action":"bump","layer":"my-layer","regions":["us-east-1","@eu"]}.
+type Invocation struct {
+	Action  string   `json:"action"`
+	Layer   string   `json:"layer"`
+	Regions []string `json:"regions"`
+}
+
+// RegionVersion is the latest layer version in a region.
+type RegionVersion struct {
+	Region  string `json:"region"`
+	Version int64  `json:"version"`
+}
+
+// InvocationResult is the structured response of the lambda function.
+type InvocationResult struct {
+	Action   string          `json:"action"`
+	Layer    string          `json:"layer"`
+	Status   string          `json:"status"`
+	Version  int64           `json:"version"`
+	Regions  []RegionVersion `json:"regions"`
+	Failures []string        `json:"failures,omitempty"`
+}
+
+// Handler handles the lambda function invocations.
+type Handler struct {
+	// Load loads the layer balancer by name.
+	Load func(name string) *layer.Balancer
+
+	// RegionGroups are the groups available in the invocation regions.
+	RegionGroups map[string][]string
+}
+
+// Handle verifies or bumps the layer of the invocation. Drift found by the
+// verify action is reported in the result, while failures return an error so
+// the invocation is flagged as failed.
+func (h *Handler) Handle(ctx context.Context, in Invocation) (*InvocationResult, error) {
+	if in.Layer == "" {
+		return nil, errors.New(`required field "layer" not set`)
+	}
+
+	if in.Action != ActionVerify && in.Action != ActionBump {
+		return nil, unknownError("action", in.Action, []string{ActionVerify, ActionBump})
+	}
+
+	regions, err := ExpandRegions(in.Regions, h.RegionGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(regions) <= 1 {
+		return nil, errors.New(`required field "regions" must contain at least two regions`)
+	}
+
+	b := h.Load(in.Layer)
+	res := &InvocationResult{Action: in.Action, Layer: in.Layer}
+
+	versions, err := b.Verify(ctx, regions)
+	if versions != nil {
+		res.observe(versions)
+	}
+
+	switch {
+	case err == nil:
+		res.Status = StatusBalanced
+		return res, nil
+	case !errors.Is(err, layer.ErrDrift):
+		return nil, err
+	case in.Action == ActionVerify:
+		res.Status = StatusDrift
+		return res, nil
+	}
+
+	plan, err := layer.NewPlan(versions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.Bump(ctx, plan); err != nil {
+		return nil, fmt.Errorf("failed to bump layer %s: %w", in.Layer, err)
+	}
+
+	versions, err = b.Verify(ctx, regions)
+	if err != nil && !errors.Is(err, layer.ErrDrift) {
+		return nil, err
+	}
+
+	res.observe(versions)
+	res.Status = StatusBumped
+
+	return res, nil
+}
+
+// observe fills the result with the latest version of each region.
+func (r *InvocationResult) observe(versions []*layer.Version) {
+	report := NewReport(r.Layer, versions)
+
+	r.Version = report.Latest
+	r.Regions = make([]RegionVersion, len(versions))
+	r.Failures = nil
+
+	for i, v := range versions {
+		r.Regions[i] = RegionVersion{Region: v.Region, Version: v.Number}
+
+		if msg := report.Failure(v); msg != "" {
+			r.Failures = append(r.Failures, fmt.Sprintf("%s: %s", v.Region, msg))
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+func TestHandlerHandle(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  string
+		svc    *mockSvc
+		status string
+		err    string
+	}{
+		{
+			name:  "missing layer",
+			event: `{"action":"verify","regions":["us-east-1","eu-west-1"]}`,
+			err:   `required field "layer" not set`,
+		},
+		{
+			name:  "unknown action",
+			event: `{"action":"bumb","layer":"my-layer","regions":["us-east-1","eu-west-1"]}`,
+			err:   `unknown action "bumb". Did you mean "bump"?`,
+		},
+		{
+			name:  "single region",
+			event: `{"action":"verify","layer":"my-layer","regions":["us-east-1"]}`,
+			err:   `required field "regions" must contain at least two regions`,
+		},
+		{
+			name:   "verify balanced",
+			event:  `{"action":"verify","layer":"my-layer","regions":["us-east-1","@eu"]}`,
+			svc:    &mockSvc{},
+			status: StatusBalanced,
+		},
+		{
+			name:   "verify drift",
+			event:  `{"action":"verify","layer":"my-layer","regions":["us-east-1","@eu"]}`,
+			svc:    driftSvc(),
+			status: StatusDrift,
+		},
+		{
+			name:   "bump drift",
+			event:  `{"action":"bump","layer":"my-layer","regions":["us-east-1","@eu"]}`,
+			svc:    driftSvc(),
+			status: StatusBumped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{
+				Load: func(name string) *layer.Balancer {
+					return mockBalancer(name, tt.svc)
+				},
+				RegionGroups: map[string][]string{"eu": {"eu-west-1"}},
+			}
+
+			var in Invocation
+			if err := json.Unmarshal([]byte(tt.event), &in); err != nil {
+				t.Fatal(err)
+			}
+
+			res, actual := h.Handle(context.Background(), in)
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if tt.err != err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if actual != nil {
+				t.SkipNow()
+			}
+
+			if res.Status != tt.status {
+				t.Errorf("expected status '%s', got '%s'", tt.status, res.Status)
+			}
+
+			if total := len(res.Regions); total != 2 {
+				t.Errorf("expected regions '2', got '%d'", total)
+			}
+		})
+	}
+}
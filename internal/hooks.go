@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// HookPayload is the JSON written to the standard input of the hook commands.
+type HookPayload struct {
+	Hook     string        `json:"hook"`
+	Layer    string        `json:"layer"`
+	Account  string        `json:"account,omitempty"`
+	Region   string        `json:"region"`
+	Artifact string        `json:"artifact,omitempty"`
+	Source   *AuditVersion `json:"source,omitempty"`
+	Target   *AuditVersion `json:"target,omitempty"`
+}
+
+// HookRunner runs the commands of each hook point with the shell.
+type HookRunner struct {
+	hooks map[string][]string
+	w     io.Writer
+}
+
+// NewHookRunner creates the runner of the hook commands by hook point, writing
+// their output to the writer.
+func NewHookRunner(hooks map[string][]string, w io.Writer) (*HookRunner, error) {
+	for name := range hooks {
+		if !slices.Contains(layer.HookNames, name) {
+			return nil, unknownError("hook", name, layer.HookNames)
+		}
+	}
+
+	return &HookRunner{hooks: hooks, w: &syncWriter{w: w}}, nil
+}
+
+// Run runs the commands of the hook point in order, passing the operation in
+// the environment and as JSON in the standard input. It stops at the first
+// command exiting with failure.
+func (r *HookRunner) Run(ctx context.Context, hc *layer.HookContext) error {
+	commands := r.hooks[hc.Hook]
+	if len(commands) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(&HookPayload{
+		Hook:     hc.Hook,
+		Layer:    hc.Layer,
+		Account:  hc.Account,
+		Region:   hc.Region,
+		Artifact: hc.Artifact,
+		Source:   auditVersion(hc.Source),
+		Target:   auditVersion(hc.Target),
+	})
+	if err != nil {
+		return err
+	}
+
+	env := append(os.Environ(), hookEnv(hc)...)
+
+	for _, command := range commands {
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Env = env
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stdout = r.w
+		cmd.Stderr = r.w
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%q: %w", command, err)
+		}
+	}
+
+	return nil
+}
+
+// hookEnv returns the environment variables describing the operation.
+func hookEnv(hc *layer.HookContext) []string {
+	env := []string{
+		"LB_HOOK=" + hc.Hook,
+		"LB_LAYER=" + hc.Layer,
+		"LB_ACCOUNT=" + hc.Account,
+		"LB_REGION=" + hc.Region,
+		"LB_ARTIFACT=" + hc.Artifact,
+	}
+
+	if v := hc.Source; v != nil {
+		env = append(env,
+			"LB_SOURCE_REGION="+v.Region,
+			"LB_SOURCE_VERSION="+strconv.FormatInt(v.Number, 10),
+			"LB_SOURCE_ARN="+v.Arn,
+			"LB_CODE_SHA256="+v.CodeSha256,
+		)
+	}
+
+	if v := hc.Target; v != nil {
+		env = append(env,
+			"LB_VERSION="+strconv.FormatInt(v.Number, 10),
+			"LB_VERSION_ARN="+v.Arn,
+		)
+	}
+
+	return env
+}
+
+// syncWriter serializes the writes of the commands run concurrently.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Write writes to the underlying writer.
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w.Write(p)
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+func TestNewHookRunner(t *testing.T) {
+	tests := []struct {
+		name  string
+		hooks map[string][]string
+		err   string
+	}{
+		{
+			name:  "valid",
+			hooks: map[string][]string{layer.HookPrePublish: {"true"}, layer.HookPostBump: {"true"}},
+		},
+		{
+			name:  "unknown hook",
+			hooks: map[string][]string{"pre-publsh": {"true"}},
+			err:   `unknown hook "pre-publsh". Did you mean "pre-publish"?`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHookRunner(tt.hooks, &bytes.Buffer{})
+
+			if err != nil && err.Error() != tt.err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if err == nil && tt.err != "" {
+				t.Errorf("expected error '%s', got nil", tt.err)
+			}
+		})
+	}
+}
+
+func TestHookRunnerRun(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	r, err := NewHookRunner(map[string][]string{
+		layer.HookPostPublish: {
+			`echo "$LB_HOOK $LB_LAYER $LB_REGION $LB_SOURCE_VERSION $LB_VERSION $LB_VERSION_ARN"`,
+			"cat",
+		},
+		layer.HookPrePublish: {"exit 3", "echo unreachable"},
+	}, out)
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	hc := &layer.HookContext{
+		Hook:   layer.HookPostPublish,
+		Layer:  "my-layer",
+		Region: "us-east-1",
+		Source: &layer.Version{Region: "eu-west-1", Number: 12, CodeSha256: "abc"},
+		Target: &layer.Version{Region: "us-east-1", Number: 11, Arn: "arn:aws:lambda:us-east-1:123456789012:layer:my-layer:11"},
+	}
+
+	if err := r.Run(context.Background(), hc); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	env, payload, _ := strings.Cut(out.String(), "\n")
+
+	expected := "post-publish my-layer us-east-1 12 11 arn:aws:lambda:us-east-1:123456789012:layer:my-layer:11"
+	if env != expected {
+		t.Errorf("expected environment '%s', got '%s'", expected, env)
+	}
+
+	p := HookPayload{}
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		t.Fatalf("expected a JSON payload, got error %v", err)
+	}
+
+	if p.Hook != layer.HookPostPublish || p.Source.Version != 12 || p.Source.CodeSha256 != "abc" || p.Target.Version != 11 {
+		t.Errorf("unexpected payload %+v", p)
+	}
+
+	out.Reset()
+	hc.Hook = layer.HookPrePublish
+
+	err = r.Run(context.Background(), hc)
+
+	expected = `"exit 3": exit status 3`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Unexpected error: %v (expected %s)", err, expected)
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("expected the commands after the failure not run, got output '%s'", out)
+	}
+
+	hc.Hook = layer.HookPostBump
+
+	if err := r.Run(context.Background(), hc); err != nil {
+		t.Errorf("expected nil for a hook without commands, got error %v", err)
+	}
+}
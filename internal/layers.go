@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// LayerOverview is the state of a layer across regions.
+type LayerOverview struct {
+	Layer    string          `json:"layer"`
+	Status   string          `json:"status"`
+	Latest   int64           `json:"latest"`
+	Versions []RegionVersion `json:"versions"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// NewLayerOverview creates the overview of the layer from the latest version
+// of each region and their balance check error.
+func NewLayerOverview(name string, versions []*layer.Version, err error) *LayerOverview {
+	o := &LayerOverview{Layer: name, Status: StatusBalanced}
+
+	for _, v := range versions {
+		o.Versions = append(o.Versions, RegionVersion{Region: v.Region, Version: v.Number})
+		o.Latest = max(o.Latest, v.Number)
+	}
+
+	switch {
+	case errors.Is(err, layer.ErrDrift):
+		o.Status = StatusDrift
+	case errors.Is(err, layer.ErrNoVersions):
+		o.Status = StatusNoVersions
+	case err != nil:
+		o.Status = StatusFailed
+		o.Error = err.Error()
+	}
+
+	return o
+}
+
+// WriteLayers writes the overviews as a table, regions without versions are
+// shown with "-".
+func WriteLayers(w io.Writer, overviews []*LayerOverview) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "LAYER\tSTATUS\tLATEST\tREGIONS")
+
+	for _, o := range overviews {
+		regions := make([]string, len(o.Versions))
+
+		for i, v := range o.Versions {
+			version := "-"
+			if v.Version > 0 {
+				version = fmt.Sprint(v.Version)
+			}
+
+			regions[i] = fmt.Sprintf("%s (%s)", v.Region, version)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", o.Layer, o.Status, o.Latest, strings.Join(regions, ", "))
+	}
+
+	return tw.Flush()
+}
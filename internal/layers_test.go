@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+func TestNewLayerOverview(t *testing.T) {
+	versions := []*layer.Version{
+		{Region: "us-east-1", Number: 3},
+		{Region: "eu-west-1", Number: 2},
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		status string
+		msg    string
+	}{
+		{name: "balanced", status: StatusBalanced},
+		{name: "drift", err: &layer.DriftError{Versions: versions}, status: StatusDrift},
+		{name: "no versions", err: layer.ErrNoVersions, status: StatusNoVersions},
+		{name: "failure", err: errors.New("access denied"), status: StatusFailed, msg: "access denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewLayerOverview("my-layer", versions, tt.err)
+
+			if o.Status != tt.status || o.Error != tt.msg {
+				t.Errorf("expected status '%s' and error '%s', got '%s' and '%s'", tt.status, tt.msg, o.Status, o.Error)
+			}
+
+			if o.Latest != 3 || len(o.Versions) != 2 {
+				t.Errorf("expected latest version '3' of 2 regions, got '%d' of %v", o.Latest, o.Versions)
+			}
+		})
+	}
+}
+
+func TestWriteLayers(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	err := WriteLayers(out, []*LayerOverview{
+		NewLayerOverview("my-layer", []*layer.Version{{Region: "us-east-1", Number: 3}, {Region: "eu-west-1", Number: 3}}, nil),
+		NewLayerOverview("other-layer", []*layer.Version{{Region: "us-east-1"}, {Region: "eu-west-1", Number: 1}}, layer.ErrDrift),
+	})
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	expected := `LAYER        STATUS    LATEST  REGIONS
+my-layer     balanced  3       us-east-1 (3), eu-west-1 (3)
+other-layer  drift     1       us-east-1 (-), eu-west-1 (1)
+`
+	if out.String() != expected {
+		t.Errorf("expected table:\n%s\ngot:\n%s", expected, out)
+	}
+}
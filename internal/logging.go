@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// log formats supported by the logger.
+const (
+	LogText = "text"
+	LogJSON = "json"
+)
+
+// LogFormats lists the supported log formats.
+var LogFormats = []string{LogText, LogJSON}
+
+// LogLevels lists the supported log levels.
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// NewLogger creates the logger writing in the format, filtering by the level.
+func NewLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, unknownError("log level", level, LogLevels)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case LogText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case LogJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, unknownError("log format", format, LogFormats)
+	}
+}
+
+// LogAPICalls returns the aws API option logging every API call at the debug
+// level, or at the warn level when it fails, e.g. for the aws config APIOptions.
+func LogAPICalls(logger *slog.Logger) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(&apiLogger{logger: logger}, middleware.After)
+	}
+}
+
+// apiLogger is the middleware logging the API calls.
+type apiLogger struct {
+	logger *slog.Logger
+}
+
+// ID returns the middleware identifier.
+func (*apiLogger) ID() string {
+	return "lbAPILogger"
+}
+
+// HandleInitialize logs the call after it completes, including its retries.
+func (m *apiLogger) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	started := time.Now()
+	out, metadata, err := next.HandleInitialize(ctx, in)
+
+	attrs := []any{
+		"region", awsmiddleware.GetRegion(ctx),
+		"operation", awsmiddleware.GetOperationName(ctx),
+		"duration", time.Since(started),
+	}
+
+	if version := versionOf(in.Parameters, out.Result); version > 0 {
+		attrs = append(attrs, "version", version)
+	}
+
+	if id := requestID(metadata, err); id != "" {
+		attrs = append(attrs, "request_id", id)
+	}
+
+	if err != nil {
+		m.logger.WarnContext(ctx, "api call failed", append(attrs, "error", err)...)
+	} else {
+		m.logger.DebugContext(ctx, "api call", attrs...)
+	}
+
+	return out, metadata, err
+}
+
+// versionOf returns the layer version of the call input or output, if any.
+func versionOf(input, result any) int64 {
+	if in, ok := input.(*lambda.GetLayerVersionInput); ok && in.VersionNumber != nil {
+		return *in.VersionNumber
+	}
+
+	if out, ok := result.(*lambda.PublishLayerVersionOutput); ok {
+		return out.Version
+	}
+
+	return 0
+}
+
+// requestID returns the aws request id of the call response or failure.
+func requestID(metadata middleware.Metadata, err error) string {
+	if id, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+		return id
+	}
+
+	var reqErr interface{ ServiceRequestID() string }
+	if errors.As(err, &reqErr) {
+		return reqErr.ServiceRequestID()
+	}
+
+	return ""
+}
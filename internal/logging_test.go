@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/faabiosr/lb/layer/layertest"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  string
+		format string
+		err    string
+	}{
+		{name: "text", level: "debug", format: "text"},
+		{name: "json", level: "warn", format: "json"},
+		{name: "unknown level", level: "debg", format: "json", err: `unknown log level "debg". Did you mean "debug"?`},
+		{name: "unknown format", level: "info", format: "jsn", err: `unknown log format "jsn". Did you mean "json"?`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, actual := NewLogger(io.Discard, tt.level, tt.format)
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if tt.err != err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLogAPICalls(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	buf := &bytes.Buffer{}
+
+	logger, err := NewLogger(buf, "debug", LogJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := aws.Config{
+		Region:       "eu-west-1",
+		BaseEndpoint: aws.String(f.URL()),
+		Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+		APIOptions:   []func(*middleware.Stack) error{LogAPICalls(logger)},
+	}
+
+	svc := lambda.NewFromConfig(cfg)
+
+	_, err = svc.PublishLayerVersion(context.Background(), &lambda.PublishLayerVersionInput{
+		LayerName: aws.String("my-layer"),
+		Content:   &types.LayerVersionContentInput{ZipFile: []byte("v1")},
+	})
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	_, err = svc.GetLayerVersion(context.Background(), &lambda.GetLayerVersionInput{
+		LayerName:     aws.String("my-layer"),
+		VersionNumber: aws.Int64(2),
+	})
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	expected := []struct {
+		level, msg, operation string
+	}{
+		{"DEBUG", "api call", "PublishLayerVersion"},
+		{"WARN", "api call failed", "GetLayerVersion"},
+	}
+
+	dec := json.NewDecoder(buf)
+
+	for _, e := range expected {
+		var entry struct {
+			Level     string `json:"level"`
+			Msg       string `json:"msg"`
+			Region    string `json:"region"`
+			Operation string `json:"operation"`
+			Version   int64  `json:"version"`
+			RequestID string `json:"request_id"`
+		}
+
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatalf("expected log entry, got error %v", err)
+		}
+
+		if entry.Level != e.level || entry.Msg != e.msg || entry.Operation != e.operation {
+			t.Errorf("expected '%s %s %s', got '%s %s %s'", e.level, e.msg, e.operation, entry.Level, entry.Msg, entry.Operation)
+		}
+
+		if entry.Region != "eu-west-1" || entry.Version == 0 || entry.RequestID == "" {
+			t.Errorf("expected region, version and request id, got %+v", entry)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// metricsNamespace is the prefix of all metric names.
+const metricsNamespace = "lb"
+
+// Metrics holds the prometheus collectors of the layer operations.
+type Metrics struct {
+	latest           *prometheus.GaugeVec
+	drift            *prometheus.GaugeVec
+	publishes        *prometheus.CounterVec
+	downloads        *prometheus.CounterVec
+	retries          prometheus.Counter
+	failures         *prometheus.CounterVec
+	downloadDuration *prometheus.HistogramVec
+	publishDuration  *prometheus.HistogramVec
+}
+
+// NewMetrics creates the layer metrics, registering them into the registerer.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		latest: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "layer_latest_version",
+			Help:      "Latest version of the layer in the region.",
+		}, []string{"layer", "region"}),
+		drift: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "layer_drift_regions",
+			Help:      "Number of regions behind the greatest version of the layer.",
+		}, []string{"layer"}),
+		publishes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "layer_publishes_total",
+			Help:      "Total of layer versions published.",
+		}, []string{"layer", "region"}),
+		downloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "layer_downloads_total",
+			Help:      "Total of layer versions downloaded.",
+		}, []string{"layer", "region"}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "api_retries_total",
+			Help:      "Total of lambda API requests retried.",
+		}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "layer_failures_total",
+			Help:      "Total of failed layer operations.",
+		}, []string{"layer", "region", "operation"}),
+		downloadDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "layer_download_duration_seconds",
+			Help:      "Duration of layer version downloads.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+		}, []string{"layer", "region"}),
+		publishDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "layer_publish_duration_seconds",
+			Help:      "Duration of layer version publishes.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+		}, []string{"layer", "region"}),
+	}
+
+	reg.MustRegister(
+		m.latest,
+		m.drift,
+		m.publishes,
+		m.downloads,
+		m.retries,
+		m.failures,
+		m.downloadDuration,
+		m.publishDuration,
+	)
+
+	return m
+}
+
+var _ layer.Observer = &Metrics{}
+
+// Versions sets the latest version of each region and the layer drift.
+func (m *Metrics) Versions(name string, versions []*layer.Version) {
+	greatest, err := layer.Greatest(versions)
+	if err != nil {
+		return
+	}
+
+	behind := 0
+
+	for _, v := range versions {
+		m.latest.WithLabelValues(name, v.Region).Set(float64(v.Number))
+
+		if !layer.SameContent(v, greatest) {
+			behind++
+		}
+	}
+
+	m.drift.WithLabelValues(name).Set(float64(behind))
+}
+
+// Operation records failures, downloads and publishes with their duration.
+func (m *Metrics) Operation(name, region, op string, d time.Duration, err error) {
+	if err != nil {
+		m.failures.WithLabelValues(name, region, op).Inc()
+		return
+	}
+
+	switch op {
+	case layer.OpDownload:
+		m.downloads.WithLabelValues(name, region).Inc()
+		m.downloadDuration.WithLabelValues(name, region).Observe(d.Seconds())
+	case layer.OpPublish:
+		m.publishes.WithLabelValues(name, region).Inc()
+		m.publishDuration.WithLabelValues(name, region).Observe(d.Seconds())
+	}
+}
+
+// Options returns the layer options recording the metrics.
+func (m *Metrics) Options() []layer.Option {
+	return []layer.Option{
+		layer.WithObserver(m),
+		layer.WithLambdaOptions(func(o *lambda.Options) {
+			o.Retryer = m.retryer(o.Retryer)
+		}),
+	}
+}
+
+// retryer wraps the retryer of the lambda client counting the retries.
+func (m *Metrics) retryer(r aws.Retryer) aws.Retryer {
+	v2, ok := r.(aws.RetryerV2)
+	if !ok {
+		return r
+	}
+
+	return &countingRetryer{RetryerV2: v2, retries: m.retries}
+}
+
+// countingRetryer counts every retry attempt of the wrapped retryer.
+type countingRetryer struct {
+	aws.RetryerV2
+	retries prometheus.Counter
+}
+
+// GetRetryToken counts the retry before delegating to the wrapped retryer.
+func (r *countingRetryer) GetRetryToken(ctx context.Context, opErr error) (func(error) error, error) {
+	r.retries.Inc()
+	return r.RetryerV2.GetRetryToken(ctx, opErr)
+}
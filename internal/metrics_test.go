@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+
+	svc := driftSvc()
+	l := layer.New(aws.Config{}, "my-layer", append(
+		m.Options(),
+		layer.WithClient(svc),
+		layer.WithHTTPClient(&http.Client{Transport: &mockResponder{}}),
+	)...)
+
+	ctx := context.Background()
+
+	if _, err := l.LatestVersions(ctx, []string{"us-east-1", "eu-west-1"}); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if got := testutil.ToFloat64(m.latest.WithLabelValues("my-layer", "us-east-1")); got != 9 {
+		t.Errorf("expected latest version '9', got '%v'", got)
+	}
+
+	if got := testutil.ToFloat64(m.drift.WithLabelValues("my-layer")); got != 1 {
+		t.Errorf("expected drift '1', got '%v'", got)
+	}
+
+	v := &layer.Version{Region: "eu-west-1", Content: &layer.Content{}}
+
+	if err := l.DownloadVersion(ctx, v, io.Discard); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if got := testutil.ToFloat64(m.downloads.WithLabelValues("my-layer", "eu-west-1")); got != 1 {
+		t.Errorf("expected downloads '1', got '%v'", got)
+	}
+
+	if _, err := l.PublishVersion(ctx, v); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if got := testutil.ToFloat64(m.publishes.WithLabelValues("my-layer", "eu-west-1")); got != 1 {
+		t.Errorf("expected publishes '1', got '%v'", got)
+	}
+
+	svc.PublishLayerVersionFn = func() (*lambda.PublishLayerVersionOutput, error) {
+		return nil, errors.New("failure")
+	}
+
+	if _, err := l.PublishVersion(ctx, v); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	if got := testutil.ToFloat64(m.failures.WithLabelValues("my-layer", "eu-west-1", layer.OpPublish)); got != 1 {
+		t.Errorf("expected failures '1', got '%v'", got)
+	}
+
+	if got := testutil.CollectAndCount(m.publishDuration); got != 1 {
+		t.Errorf("expected publish duration series '1', got '%d'", got)
+	}
+}
+
+func TestMetricsRetryer(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+	r := retry.NewStandard()
+
+	_, _ = m.retryer(r).GetRetryToken(context.Background(), errors.New("failure"))
+
+	if got := testutil.ToFloat64(m.retries); got != 1 {
+		t.Errorf("expected retries '1', got '%v'", got)
+	}
+}
@@ -7,14 +7,18 @@ package internal
 
 import (
 	"context"
-	"errors"
 	"io"
 	"net/http"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/account"
+	accounttypes "github.com/aws/aws-sdk-go-v2/service/account/types"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/faabiosr/lb/layer"
 )
 
 // Type alias to lambda options
@@ -24,9 +28,11 @@ type mockSvc struct {
 	GetLayerVersionFn     func() (*lambda.GetLayerVersionOutput, error)
 	ListLayerVersionsFn   func(...mockOpts) (*lambda.ListLayerVersionsOutput, error)
 	PublishLayerVersionFn func() (*lambda.PublishLayerVersionOutput, error)
+	DeleteLayerVersionFn  func(*lambda.DeleteLayerVersionInput) (*lambda.DeleteLayerVersionOutput, error)
+	ListLayersFn          func(...mockOpts) (*lambda.ListLayersOutput, error)
 }
 
-var _ svc = &mockSvc{}
+var _ layer.Client = &mockSvc{}
 
 func (m *mockSvc) GetLayerVersion(context.Context, *lambda.GetLayerVersionInput, ...mockOpts) (*lambda.GetLayerVersionOutput, error) {
 	if m.GetLayerVersionFn != nil {
@@ -64,12 +70,20 @@ func (m *mockSvc) PublishLayerVersion(context.Context, *lambda.PublishLayerVersi
 	return &lambda.PublishLayerVersionOutput{}, nil
 }
 
-type mockWriter struct{}
+func (m *mockSvc) DeleteLayerVersion(_ context.Context, in *lambda.DeleteLayerVersionInput, _ ...mockOpts) (*lambda.DeleteLayerVersionOutput, error) {
+	if m.DeleteLayerVersionFn != nil {
+		return m.DeleteLayerVersionFn(in)
+	}
+
+	return &lambda.DeleteLayerVersionOutput{}, nil
+}
 
-var _ io.Writer = &mockWriter{}
+func (m *mockSvc) ListLayers(_ context.Context, _ *lambda.ListLayersInput, opts ...mockOpts) (*lambda.ListLayersOutput, error) {
+	if m.ListLayersFn != nil {
+		return m.ListLayersFn(opts...)
+	}
 
-func (w *mockWriter) Write(p []byte) (int, error) {
-	return 0, errors.New("failed")
+	return &lambda.ListLayersOutput{}, nil
 }
 
 type mockResponder struct {
@@ -84,6 +98,52 @@ func (m *mockResponder) RoundTrip(r *http.Request) (*http.Response, error) {
 	}
 
 	return &http.Response{
-		Body: io.NopCloser(strings.NewReader("content")),
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("content")),
+	}, nil
+}
+
+type mockAccountSvc struct {
+	ListRegionsFn func() (*account.ListRegionsOutput, error)
+}
+
+var _ accountSvc = &mockAccountSvc{}
+
+func (m *mockAccountSvc) ListRegions(context.Context, *account.ListRegionsInput, ...func(*account.Options)) (*account.ListRegionsOutput, error) {
+	if m.ListRegionsFn != nil {
+		return m.ListRegionsFn()
+	}
+
+	return &account.ListRegionsOutput{
+		Regions: []accounttypes.Region{
+			{RegionName: aws.String("us-west-2"), RegionOptStatus: accounttypes.RegionOptStatusEnabledByDefault},
+			{RegionName: aws.String("af-south-1"), RegionOptStatus: accounttypes.RegionOptStatusDisabled},
+			{RegionName: aws.String("eu-south-1"), RegionOptStatus: accounttypes.RegionOptStatusEnabled},
+			{RegionName: aws.String("us-east-1"), RegionOptStatus: accounttypes.RegionOptStatusEnabledByDefault},
+			{RegionName: aws.String("me-south-1"), RegionOptStatus: accounttypes.RegionOptStatusEnabling},
+		},
+	}, nil
+}
+
+// mockBalancer creates a balancer of the layer using the mock service.
+func mockBalancer(name string, svc *mockSvc) *layer.Balancer {
+	return layer.NewBalancer(aws.Config{}, name,
+		layer.WithClient(svc),
+		layer.WithHTTPClient(&http.Client{Transport: &mockResponder{}}),
+	)
+}
+
+type mockIdentitySvc struct {
+	err error
+}
+
+func (m *mockIdentitySvc) GetCallerIdentity(context.Context, *sts.GetCallerIdentityInput, ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &sts.GetCallerIdentityOutput{
+		Account: aws.String("123456789012"),
+		Arn:     aws.String("arn:aws:iam::123456789012:user/lb"),
 	}, nil
 }
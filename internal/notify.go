@@ -0,0 +1,266 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// notification events sent by the commands.
+const (
+	EventBumpCompleted = "bump_completed"
+	EventBumpFailed    = "bump_failed"
+	EventVerifyPassed  = "verify_passed"
+	EventDriftDetected = "drift_detected"
+	EventVerifyFailed  = "verify_failed"
+)
+
+// NotificationEvents lists the supported notification events.
+var NotificationEvents = []string{
+	EventBumpCompleted,
+	EventBumpFailed,
+	EventVerifyPassed,
+	EventDriftDetected,
+	EventVerifyFailed,
+}
+
+// DefaultNotificationEvents are the events of a sink without events, the
+// failures and drift, as the other events are sent on every successful run.
+var DefaultNotificationEvents = []string{
+	EventBumpFailed,
+	EventDriftDetected,
+	EventVerifyFailed,
+}
+
+// notification sink types.
+const (
+	SinkWebhook = "webhook"
+	SinkSlack   = "slack"
+)
+
+// SinkTypes lists the supported notification sink types.
+var SinkTypes = []string{SinkWebhook, SinkSlack}
+
+// defaultMessages are the message templates of each event.
+var defaultMessages = map[string]string{
+	EventBumpCompleted: `Layer {{.Layer}} bumped to version {{.Version}} in {{join .Changed ", "}}`,
+	EventBumpFailed:    `Layer {{.Layer}} bump failed: {{.Error}}`,
+	EventVerifyPassed:  `Layer {{.Layer}} version {{.Version}} is balanced across {{join .Regions ", "}}`,
+	EventDriftDetected: `Layer {{.Layer}} drift detected: {{.Error}}`,
+	EventVerifyFailed:  `Layer {{.Layer}} verification failed: {{.Error}}`,
+}
+
+// templateFuncs are the functions available to the message templates.
+var templateFuncs = template.FuncMap{"join": strings.Join}
+
+// NotificationConfig represents a notification sink of the configuration file.
+// The url and header values expand the environment variables, e.g. ${SLACK_URL}.
+type NotificationConfig struct {
+	Type     string            `json:"type"`
+	URL      string            `json:"url"`
+	Events   []string          `json:"events"`
+	Template string            `json:"template"`
+	Headers  map[string]string `json:"headers"`
+}
+
+// Notification describes the result of a command sent to the sinks.
+type Notification struct {
+	Time     time.Time        `json:"time"`
+	Event    string           `json:"event"`
+	Layer    string           `json:"layer"`
+	Version  int64            `json:"version,omitempty"`
+	Regions  []string         `json:"regions,omitempty"`
+	Changed  []string         `json:"changed,omitempty"`
+	Versions map[string]int64 `json:"versions,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Message  string           `json:"message"`
+}
+
+// NewNotification creates the notification of the event with the latest
+// version of each region, where the version is the greatest one.
+func NewNotification(event, name string, versions []*layer.Version, err error) *Notification {
+	n := &Notification{
+		Time:  time.Now().UTC(),
+		Event: event,
+		Layer: name,
+	}
+
+	if len(versions) > 0 {
+		n.Versions = make(map[string]int64, len(versions))
+
+		for _, v := range versions {
+			n.Version = max(n.Version, v.Number)
+			n.Regions = append(n.Regions, v.Region)
+			n.Versions[v.Region] = v.Number
+		}
+	}
+
+	if err != nil {
+		n.Error = err.Error()
+	}
+
+	return n
+}
+
+// Notifier sends the notifications to the sinks subscribed to their events.
+type Notifier struct {
+	sinks []*sink
+	hc    *http.Client
+}
+
+// sink is a notification sink with its parsed message templates.
+type sink struct {
+	kind      string
+	url       string
+	events    []string
+	headers   map[string]string
+	templates map[string]*template.Template
+}
+
+// NewNotifier creates the notifier of the sink configurations, validating
+// their types, events and templates.
+func NewNotifier(configs []NotificationConfig, hc *http.Client) (*Notifier, error) {
+	n := &Notifier{hc: hc}
+
+	for i, c := range configs {
+		s, err := newSink(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification %d: %w", i+1, err)
+		}
+
+		n.sinks = append(n.sinks, s)
+	}
+
+	return n, nil
+}
+
+// newSink creates the sink of the configuration, subscribed to the default
+// events when none is defined.
+func newSink(c NotificationConfig) (*sink, error) {
+	kind := strings.ToLower(c.Type)
+	if !slices.Contains(SinkTypes, kind) {
+		return nil, unknownError("notification type", c.Type, SinkTypes)
+	}
+
+	url := os.ExpandEnv(c.URL)
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("invalid url %q, expected an http or https url", c.URL)
+	}
+
+	s := &sink{
+		kind:      kind,
+		url:       url,
+		events:    c.Events,
+		headers:   make(map[string]string, len(c.Headers)),
+		templates: make(map[string]*template.Template, len(defaultMessages)),
+	}
+
+	for _, event := range c.Events {
+		if !slices.Contains(NotificationEvents, event) {
+			return nil, unknownError("notification event", event, NotificationEvents)
+		}
+	}
+
+	if len(s.events) == 0 {
+		s.events = DefaultNotificationEvents
+	}
+
+	for key, value := range c.Headers {
+		s.headers[key] = os.ExpandEnv(value)
+	}
+
+	for event, text := range defaultMessages {
+		if c.Template != "" {
+			text = c.Template
+		}
+
+		tmpl, err := template.New(event).Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+
+		s.templates[event] = tmpl
+	}
+
+	return s, nil
+}
+
+// Notify sends the notification to every sink subscribed to its event,
+// returning the failures of all sinks.
+func (n *Notifier) Notify(ctx context.Context, notification *Notification) error {
+	var errs []error
+
+	for _, s := range n.sinks {
+		if !slices.Contains(s.events, notification.Event) {
+			continue
+		}
+
+		if err := s.send(ctx, n.hc, *notification); err != nil {
+			errs = append(errs, fmt.Errorf("failed to notify %s: %w", s.kind, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send renders the message of the notification and posts the payload of
+// the sink type.
+func (s *sink) send(ctx context.Context, hc *http.Client, n Notification) error {
+	msg := &strings.Builder{}
+	if err := s.templates[n.Event].Execute(msg, n); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+
+	n.Message = msg.String()
+
+	var payload any = n
+	if s.kind == SinkSlack {
+		payload = map[string]string{"text": n.Message}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() // nolint:errcheck
+
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	return nil
+}
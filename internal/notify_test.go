@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// receiver is an http server recording the received JSON payloads.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	status   int
+	payloads []map[string]any
+	headers  []http.Header
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	t.Helper()
+
+	r := &receiver{status: status}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		payload := map[string]any{}
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			t.Errorf("expected a JSON payload, got error %v", err)
+		}
+
+		r.mu.Lock()
+		r.payloads = append(r.payloads, payload)
+		r.headers = append(r.headers, req.Header)
+		r.mu.Unlock()
+
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func TestNewNotifier(t *testing.T) {
+	tests := []struct {
+		name   string
+		config NotificationConfig
+		err    string
+	}{
+		{
+			name:   "valid",
+			config: NotificationConfig{Type: "slack", URL: "https://hooks.slack.com/services/T/B/X", Events: []string{EventDriftDetected}},
+		},
+		{
+			name:   "unknown type",
+			config: NotificationConfig{Type: "email", URL: "https://example.com"},
+			err:    `invalid notification 1: unknown notification type "email"`,
+		},
+		{
+			name:   "invalid url",
+			config: NotificationConfig{Type: "webhook", URL: "example.com"},
+			err:    `invalid notification 1: invalid url "example.com", expected an http or https url`,
+		},
+		{
+			name:   "unknown event",
+			config: NotificationConfig{Type: "webhook", URL: "https://example.com", Events: []string{"bump_complete"}},
+			err:    `invalid notification 1: unknown notification event "bump_complete". Did you mean "bump_completed"?`,
+		},
+		{
+			name:   "invalid template",
+			config: NotificationConfig{Type: "webhook", URL: "https://example.com", Template: "{{.Layer"},
+			err:    "invalid notification 1: invalid template:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewNotifier([]NotificationConfig{tt.config}, http.DefaultClient)
+
+			if err != nil && (tt.err == "" || !strings.HasPrefix(err.Error(), tt.err)) {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if err == nil && tt.err != "" {
+				t.Errorf("expected error '%s', got nil", tt.err)
+			}
+		})
+	}
+}
+
+func TestNotify(t *testing.T) {
+	webhook := newReceiver(t, http.StatusOK)
+	slack := newReceiver(t, http.StatusOK)
+
+	t.Setenv("SLACK_URL", slack.URL)
+	t.Setenv("WEBHOOK_TOKEN", "secret")
+
+	n, err := NewNotifier([]NotificationConfig{
+		{
+			Type:    "webhook",
+			URL:     webhook.URL,
+			Headers: map[string]string{"Authorization": "Bearer ${WEBHOOK_TOKEN}"},
+		},
+		{
+			Type:     "slack",
+			URL:      "${SLACK_URL}",
+			Events:   []string{EventDriftDetected},
+			Template: `:warning: {{.Layer}} behind in {{len .Regions}} regions: {{.Error}}`,
+		},
+	}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	versions := []*layer.Version{
+		{Region: "us-east-1", Number: 2},
+		{Region: "eu-west-1", Number: 1},
+	}
+
+	drift := &layer.DriftError{Versions: versions}
+
+	for _, notification := range []*Notification{
+		NewNotification(EventVerifyPassed, "my-layer", versions[:1], nil),
+		NewNotification(EventDriftDetected, "my-layer", versions, drift),
+	} {
+		if err := n.Notify(context.Background(), notification); err != nil {
+			t.Fatalf("expected nil, got error %v", err)
+		}
+	}
+
+	// the sink without events is not subscribed to verify_passed.
+	if total := len(webhook.payloads); total != 1 {
+		t.Fatalf("expected webhook payloads '1', got '%d'", total)
+	}
+
+	payload := webhook.payloads[0]
+	if payload["event"] != EventDriftDetected || payload["layer"] != "my-layer" || payload["version"] != float64(2) {
+		t.Errorf("unexpected webhook payload %v", payload)
+	}
+
+	expected := "Layer my-layer drift detected: " + drift.Error()
+	if payload["message"] != expected {
+		t.Errorf("expected message '%s', got '%v'", expected, payload["message"])
+	}
+
+	if auth := webhook.headers[0].Get("Authorization"); auth != "Bearer secret" {
+		t.Errorf("expected authorization 'Bearer secret', got '%s'", auth)
+	}
+
+	if total := len(slack.payloads); total != 1 {
+		t.Fatalf("expected slack payloads '1', got '%d'", total)
+	}
+
+	expected = ":warning: my-layer behind in 2 regions: " + drift.Error()
+	if text := slack.payloads[0]["text"]; text != expected || len(slack.payloads[0]) != 1 {
+		t.Errorf("expected slack payload with text '%s', got %v", expected, slack.payloads[0])
+	}
+}
+
+func TestNotifyFailure(t *testing.T) {
+	failing := newReceiver(t, http.StatusInternalServerError)
+	ok := newReceiver(t, http.StatusNoContent)
+
+	n, err := NewNotifier([]NotificationConfig{
+		{Type: "webhook", URL: failing.URL},
+		{Type: "webhook", URL: ok.URL},
+	}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	err = n.Notify(context.Background(), NewNotification(EventBumpFailed, "my-layer", nil, errors.New("boom")))
+	if err == nil || !strings.Contains(err.Error(), "failed to notify webhook: unexpected status 500") {
+		t.Errorf("expected a status error, got %v", err)
+	}
+
+	if total := len(ok.payloads); total != 1 {
+		t.Errorf("expected the other sink notified, got payloads '%d'", total)
+	}
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/account"
+	"github.com/aws/aws-sdk-go-v2/service/account/types"
+	"github.com/xrash/smetrics"
+)
+
+// accountRegion is the region used to reach the global account endpoint.
+const accountRegion = "us-east-1"
+
+// groupPrefix identifies a region group name in a list of regions.
+const groupPrefix = "@"
+
+// KnownRegions is the list of AWS region codes supporting lambda layers. New
+// regions are listed by the public SSM parameters of the lambda service:
+//
+//	aws ssm get-parameters-by-path \
+//	  --path /aws/service/global-infrastructure/services/lambda/regions \
+//	  --query 'Parameters[].Value' --output text
+var KnownRegions = []string{
+	"af-south-1",
+	"ap-east-1",
+	"ap-east-2",
+	"ap-northeast-1",
+	"ap-northeast-2",
+	"ap-northeast-3",
+	"ap-south-1",
+	"ap-south-2",
+	"ap-southeast-1",
+	"ap-southeast-2",
+	"ap-southeast-3",
+	"ap-southeast-4",
+	"ap-southeast-5",
+	"ap-southeast-6",
+	"ap-southeast-7",
+	"ca-central-1",
+	"ca-west-1",
+	"cn-north-1",
+	"cn-northwest-1",
+	"eu-central-1",
+	"eu-central-2",
+	"eu-north-1",
+	"eu-south-1",
+	"eu-south-2",
+	"eu-west-1",
+	"eu-west-2",
+	"eu-west-3",
+	"il-central-1",
+	"me-central-1",
+	"me-south-1",
+	"mx-central-1",
+	"sa-east-1",
+	"us-east-1",
+	"us-east-2",
+	"us-gov-east-1",
+	"us-gov-west-1",
+	"us-west-1",
+	"us-west-2",
+}
+
+type accountSvc interface {
+	ListRegions(context.Context, *account.ListRegionsInput, ...func(*account.Options)) (*account.ListRegionsOutput, error)
+}
+
+// Account represents the AWS account which owns the lambda layers.
+type Account struct {
+	svc accountSvc
+}
+
+// LoadAccount loads the account information.
+func LoadAccount(cfg aws.Config) *Account {
+	return &Account{
+		svc: account.NewFromConfig(cfg, func(o *account.Options) {
+			if o.Region == "" {
+				o.Region = accountRegion
+			}
+		}),
+	}
+}
+
+// Regions holds the account regions split by their opt-in status.
+type Regions struct {
+	Enabled  []string
+	Disabled []string
+}
+
+// Regions retrieves all regions of the account, the regions that are not
+// enabled (e.g. opt-in regions not enabled yet) are listed as disabled.
+func (a *Account) Regions(ctx context.Context) (*Regions, error) {
+	regions := &Regions{}
+
+	p := account.NewListRegionsPaginator(a.svc, &account.ListRegionsInput{})
+	for p.HasMorePages() {
+		out, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list account regions: %w", err)
+		}
+
+		for _, r := range out.Regions {
+			name := aws.ToString(r.RegionName)
+
+			switch r.RegionOptStatus {
+			case types.RegionOptStatusEnabled, types.RegionOptStatusEnabledByDefault:
+				regions.Enabled = append(regions.Enabled, name)
+			default:
+				regions.Disabled = append(regions.Disabled, name)
+			}
+		}
+	}
+
+	slices.Sort(regions.Enabled)
+	slices.Sort(regions.Disabled)
+
+	return regions, nil
+}
+
+// Exclude removes the excluded regions from the list, keeping the order.
+func Exclude(regions, excluded []string) []string {
+	return slices.DeleteFunc(slices.Clone(regions), func(r string) bool {
+		return slices.Contains(excluded, r)
+	})
+}
+
+// ExpandRegions expands the region groups (e.g. "@eu") and validates the region
+// codes, returning the de-duplicated list of regions.
+func ExpandRegions(values []string, groups map[string][]string) ([]string, error) {
+	var regions []string
+
+	for _, value := range values {
+		items := []string{value}
+
+		if name, ok := strings.CutPrefix(value, groupPrefix); ok {
+			group, found := groups[name]
+			if !found {
+				return nil, unknownError("region group", name, mapKeys(groups))
+			}
+
+			items = group
+		}
+
+		for _, r := range items {
+			if !slices.Contains(KnownRegions, r) {
+				return nil, unknownError("region", r, KnownRegions)
+			}
+
+			if !slices.Contains(regions, r) {
+				regions = append(regions, r)
+			}
+		}
+	}
+
+	return regions, nil
+}
+
+// unknownError creates an error for an unknown value, suggesting the closest candidate.
+func unknownError(kind, provided string, candidates []string) error {
+	if suggestion := suggest(provided, candidates); suggestion != "" {
+		return fmt.Errorf("unknown %s %q. Did you mean %q?", kind, provided, suggestion)
+	}
+
+	return fmt.Errorf("unknown %s %q", kind, provided)
+}
+
+// suggest returns the closest candidate of the provided value, unless every
+// candidate is more than a third of the value length of edits away, e.g. for a
+// typo but not for an unrelated value.
+func suggest(provided string, candidates []string) string {
+	distance := max(1, len(provided)/3) + 1
+	suggestion := ""
+
+	for _, c := range candidates {
+		if d := smetrics.WagnerFischer(c, provided, 1, 1, 1); d < distance {
+			distance = d
+			suggestion = c
+		}
+	}
+
+	return suggestion
+}
+
+// mapKeys returns the sorted keys of the map.
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
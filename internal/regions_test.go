@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/account"
+)
+
+func TestAccountRegions(t *testing.T) {
+	t.Run("response failure", func(t *testing.T) {
+		a := &Account{
+			svc: &mockAccountSvc{
+				ListRegionsFn: func() (*account.ListRegionsOutput, error) {
+					return nil, errors.New("failure")
+				},
+			},
+		}
+
+		_, err := a.Regions(context.Background())
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("enabled and disabled regions", func(t *testing.T) {
+		a := &Account{svc: &mockAccountSvc{}}
+
+		got, err := a.Regions(context.Background())
+		if err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		enabled := []string{"eu-south-1", "us-east-1", "us-west-2"}
+		if !slices.Equal(got.Enabled, enabled) {
+			t.Errorf("expected enabled regions %v, got %v", enabled, got.Enabled)
+		}
+
+		disabled := []string{"af-south-1", "me-south-1"}
+		if !slices.Equal(got.Disabled, disabled) {
+			t.Errorf("expected disabled regions %v, got %v", disabled, got.Disabled)
+		}
+	})
+}
+
+func TestExclude(t *testing.T) {
+	regions := []string{"us-east-1", "eu-south-1", "us-west-2"}
+
+	got := Exclude(regions, []string{"eu-south-1", "sa-east-1"})
+	expected := []string{"us-east-1", "us-west-2"}
+
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected regions %v, got %v", expected, got)
+	}
+
+	if len(regions) != 3 {
+		t.Errorf("expected source regions untouched, got %v", regions)
+	}
+}
+
+func TestExpandRegions(t *testing.T) {
+	groups := map[string][]string{
+		"eu":           {"eu-west-1", "eu-central-1"},
+		"prod-primary": {"us-east-1", "eu-west-1"},
+	}
+
+	tests := []struct {
+		name     string
+		values   []string
+		expected []string
+		err      string
+	}{
+		{
+			name:     "regions and groups",
+			values:   []string{"@eu", "us-east-1", "@prod-primary"},
+			expected: []string{"eu-west-1", "eu-central-1", "us-east-1"},
+		},
+		{
+			name:   "unknown region",
+			values: []string{"us-est-1"},
+			err:    `unknown region "us-est-1". Did you mean "us-east-1"?`,
+		},
+		{
+			name:   "unrelated region",
+			values: []string{"foo"},
+			err:    `unknown region "foo"`,
+		},
+		{
+			name:   "unknown group",
+			values: []string{"@prod-primry"},
+			err:    `unknown region group "prod-primry". Did you mean "prod-primary"?`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, actual := ExpandRegions(tt.values, groups)
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if tt.err != err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected regions %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
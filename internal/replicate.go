@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// AccountTarget is an account receiving the layer replicas, through the role
+// assumed in the account.
+type AccountTarget struct {
+	Account string
+	RoleArn string
+	Regions []string
+}
+
+// ParseTargets parses the replication targets in the format "role-arn=region",
+// where the region can be a region group (e.g. "@eu"), merging the regions of
+// the same role.
+func ParseTargets(values []string, groups map[string][]string) ([]*AccountTarget, error) {
+	var targets []*AccountTarget
+
+	for _, value := range values {
+		role, region, ok := strings.Cut(value, "=")
+		if !ok || region == "" {
+			return nil, fmt.Errorf(`invalid target %q, expected "role-arn=region"`, value)
+		}
+
+		parsed, err := arn.Parse(role)
+		if err != nil || parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+			return nil, fmt.Errorf("invalid target role arn %q", role)
+		}
+
+		regions, err := ExpandRegions([]string{region}, groups)
+		if err != nil {
+			return nil, err
+		}
+
+		idx := slices.IndexFunc(targets, func(t *AccountTarget) bool {
+			return t.RoleArn == role
+		})
+
+		if idx < 0 {
+			targets = append(targets, &AccountTarget{Account: parsed.AccountID, RoleArn: role})
+			idx = len(targets) - 1
+		}
+
+		for _, r := range regions {
+			if !slices.Contains(targets[idx].Regions, r) {
+				targets[idx].Regions = append(targets[idx].Regions, r)
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// WriteReplications writes the result of each account and region as a table.
+func WriteReplications(w io.Writer, results []*layer.Replication) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ACCOUNT\tREGION\tPREVIOUS\tVERSION\tSTATUS")
+
+	for _, r := range results {
+		status := "up to date"
+
+		switch {
+		case r.Err != nil:
+			status = fmt.Sprintf("failed: %v", errorCause(r.Err))
+		case r.Published > 0:
+			status = fmt.Sprintf("replicated %d versions", r.Published)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", r.Account, r.Region, r.Previous, r.Version(), status)
+	}
+
+	return tw.Flush()
+}
+
+// errorCause returns the error without the account and region prefixes.
+func errorCause(err error) error {
+	for {
+		switch e := err.(type) {
+		case *layer.AccountError:
+			err = e.Err
+		case *layer.RegionError:
+			err = e.Err
+		default:
+			return err
+		}
+	}
+}
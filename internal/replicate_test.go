@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+func TestParseTargets(t *testing.T) {
+	groups := map[string][]string{"eu": {"eu-west-1", "eu-central-1"}}
+	role := "arn:aws:iam::111111111111:role/lb"
+
+	tests := []struct {
+		name     string
+		values   []string
+		expected []*AccountTarget
+		err      string
+	}{
+		{
+			name:   "regions and groups merged by role",
+			values: []string{role + "=us-east-1", role + "=@eu", "arn:aws:iam::222222222222:role/lb=eu-west-1"},
+			expected: []*AccountTarget{
+				{Account: "111111111111", RoleArn: role, Regions: []string{"us-east-1", "eu-west-1", "eu-central-1"}},
+				{Account: "222222222222", RoleArn: "arn:aws:iam::222222222222:role/lb", Regions: []string{"eu-west-1"}},
+			},
+		},
+		{
+			name:   "missing region",
+			values: []string{role},
+			err:    `invalid target "arn:aws:iam::111111111111:role/lb", expected "role-arn=region"`,
+		},
+		{
+			name:   "invalid role",
+			values: []string{"arn:aws:iam::111111111111:user/lb=us-east-1"},
+			err:    `invalid target role arn "arn:aws:iam::111111111111:user/lb"`,
+		},
+		{
+			name:   "unknown region",
+			values: []string{role + "=us-est-1"},
+			err:    `unknown region "us-est-1". Did you mean "us-east-1"?`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, actual := ParseTargets(tt.values, groups)
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if tt.err != err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected targets %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteReplications(t *testing.T) {
+	results := []*layer.Replication{
+		{Account: "111111111111", Region: "eu-west-1", Previous: 10},
+		{Account: "111111111111", Region: "us-east-1", Previous: 8, Published: 2, Latest: 10},
+		{
+			Account:  "222222222222",
+			Region:   "us-east-1",
+			Previous: 8,
+			Err: &layer.AccountError{
+				Account: "222222222222",
+				Err:     &layer.RegionError{Region: "us-east-1", Err: errors.New("access denied")},
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteReplications(buf, results); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	expected := []string{
+		"ACCOUNT       REGION     PREVIOUS  VERSION  STATUS",
+		"111111111111  eu-west-1  10        10       up to date",
+		"111111111111  us-east-1  8         10       replicated 2 versions",
+		"222222222222  us-east-1  8         8        failed: access denied",
+		"",
+	}
+
+	if got := buf.String(); got != strings.Join(expected, "\n") {
+		t.Errorf("unexpected table:\n%s", got)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// report formats supported by the verify command.
+const (
+	ReportJUnit    = "junit"
+	ReportMarkdown = "markdown"
+)
+
+// ReportFormats lists the supported report formats.
+var ReportFormats = []string{ReportJUnit, ReportMarkdown}
+
+// Report holds the verification result of the layer across regions.
+type Report struct {
+	Layer    string
+	Versions []*layer.Version
+	Latest   int64
+
+	// source is the greatest version, the regions are compared with.
+	source *layer.Version
+}
+
+// NewReport creates a report from the latest version of each region.
+func NewReport(name string, versions []*layer.Version) *Report {
+	r := &Report{Layer: name, Versions: versions}
+
+	if greatest, err := layer.Greatest(versions); err == nil {
+		r.Latest = greatest.Number
+		r.source = greatest
+	}
+
+	return r
+}
+
+// Failure returns the reason why the region version is not balanced, or an
+// empty string when it has the content of the greatest version.
+func (r *Report) Failure(v *layer.Version) string {
+	switch {
+	case r.Latest == 0:
+		return "no published versions"
+	case layer.SameContent(v, r.source):
+		return ""
+	case v.Number < r.Latest:
+		return fmt.Sprintf("behind, version %d of %d", v.Number, r.Latest)
+	default:
+		return fmt.Sprintf("content differs, version %d of %d", v.Number, r.Latest)
+	}
+}
+
+// Failures returns the number of regions not balanced.
+func (r *Report) Failures() int {
+	total := 0
+
+	for _, v := range r.Versions {
+		if r.Failure(v) != "" {
+			total++
+		}
+	}
+
+	return total
+}
+
+// Write writes the report in the format to the writer.
+func (r *Report) Write(format string, w io.Writer) error {
+	switch format {
+	case ReportJUnit:
+		return r.WriteJUnit(w)
+	case ReportMarkdown:
+		return r.WriteMarkdown(w)
+	default:
+		return unknownError("report format", format, ReportFormats)
+	}
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name `xml:"testsuites"`
+	Suites  []junitTestSuite
+}
+
+// WriteJUnit writes the report as JUnit XML, with one test case per region.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:     "lb verify " + r.Layer,
+		Tests:    len(r.Versions),
+		Failures: r.Failures(),
+	}
+
+	for _, v := range r.Versions {
+		tc := junitTestCase{Name: v.Region, ClassName: r.Layer}
+
+		if msg := r.Failure(v); msg != "" {
+			tc.Failure = &junitFailure{Message: msg, Type: "drift"}
+		}
+
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write junit report: %w", err)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return fmt.Errorf("failed to write junit report: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Parse(`## Layer ` + "`{{.Layer}}`" + ` verification
+
+| Region | Version | Status |
+|--------|---------|--------|
+{{- range .Versions}}
+| {{.Region}} | {{.Number}} | {{with $.Failure .}}:x: {{.}}{{else}}:white_check_mark: up to date{{end}} |
+{{- end}}
+
+{{if .Failures}}**{{.Failures}} of {{len .Versions}} regions are not balanced.**{{else}}**All regions are balanced at version {{.Latest}}.**{{end}}
+`))
+
+// WriteMarkdown writes the report as a markdown summary table.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	if err := markdownTemplate.Execute(w, r); err != nil {
+		return fmt.Errorf("failed to write markdown report: %w", err)
+	}
+
+	return nil
+}
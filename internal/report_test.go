@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+func TestReportWrite(t *testing.T) {
+	versions := []*layer.Version{
+		{Region: "us-east-1", Number: 3},
+		{Region: "eu-west-1", Number: 2},
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+		err      string
+	}{
+		{
+			name:   "unknown format",
+			format: "junt",
+			err:    `unknown report format "junt". Did you mean "junit"?`,
+		},
+		{
+			name:   "junit",
+			format: ReportJUnit,
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="lb verify my-layer" tests="2" failures="1">
+    <testcase name="us-east-1" classname="my-layer"></testcase>
+    <testcase name="eu-west-1" classname="my-layer">
+      <failure message="behind, version 2 of 3" type="drift"></failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+		},
+		{
+			name:   "markdown",
+			format: ReportMarkdown,
+			expected: "## Layer `my-layer` verification\n\n" +
+				"| Region | Version | Status |\n" +
+				"|--------|---------|--------|\n" +
+				"| us-east-1 | 3 | :white_check_mark: up to date |\n" +
+				"| eu-west-1 | 2 | :x: behind, version 2 of 3 |\n\n" +
+				"**1 of 2 regions are not balanced.**\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			actual := NewReport("my-layer", versions).Write(tt.format, buf)
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if tt.err != err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if got := buf.String(); got != tt.expected {
+				t.Errorf("expected report:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestReportNoVersions(t *testing.T) {
+	r := NewReport("my-layer", []*layer.Version{{Region: "us-east-1"}, {Region: "eu-west-1"}})
+
+	if total := r.Failures(); total != 2 {
+		t.Errorf("expected failures '2', got '%d'", total)
+	}
+}
+
+func TestReportFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []*layer.Version
+		expected string
+	}{
+		{
+			name: "same content behind in number",
+			versions: []*layer.Version{
+				{Region: "us-east-1", Number: 1, CodeSha256: "sha"},
+				{Region: "eu-west-1", Number: 2, CodeSha256: "sha"},
+			},
+		},
+		{
+			name: "different content",
+			versions: []*layer.Version{
+				{Region: "us-east-1", Number: 2, CodeSha256: "other"},
+				{Region: "eu-west-1", Number: 2, CodeSha256: "sha"},
+			},
+			expected: "content differs, version 2 of 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReport("my-layer", tt.versions)
+
+			if msg := r.Failure(tt.versions[1]); msg != tt.expected {
+				t.Errorf("expected failure '%s', got '%s'", tt.expected, msg)
+			}
+		})
+	}
+}
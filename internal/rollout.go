@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// RestRegions is the wave region selecting the regions not in other waves.
+const RestRegions = "*"
+
+// RolloutConfig represents a progressive rollout of the configuration file.
+type RolloutConfig struct {
+	Waves []WaveConfig `json:"waves"`
+
+	// HealthCheck is the command run between waves, a failure stops the rollout.
+	HealthCheck string `json:"health_check"`
+}
+
+// WaveConfig represents the regions (or region groups) of a rollout wave and
+// the time waited after bumping them, e.g. "15m".
+type WaveConfig struct {
+	Regions  []string `json:"regions"`
+	BakeTime string   `json:"bake_time"`
+}
+
+// ResolveWaves resolves the waves of the rollout for the bumped regions, the
+// regions of the waves not being bumped are left out, as well as the waves
+// without regions. Every bumped region must belong to a single wave, where
+// the "*" wave holds the regions not in other waves.
+func ResolveWaves(c RolloutConfig, regions []string, groups map[string][]string) ([]layer.Wave, error) {
+	if len(c.Waves) == 0 {
+		return nil, fmt.Errorf("rollout has no waves")
+	}
+
+	waves := make([]layer.Wave, len(c.Waves))
+	assigned := map[string]int{}
+	rest := -1
+
+	for i, wc := range c.Waves {
+		if wc.BakeTime != "" {
+			d, err := time.ParseDuration(wc.BakeTime)
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("invalid bake time %q of wave %d", wc.BakeTime, i+1)
+			}
+
+			waves[i].BakeTime = d
+		}
+
+		values := slices.DeleteFunc(slices.Clone(wc.Regions), func(r string) bool {
+			return r == RestRegions
+		})
+
+		if len(values) != len(wc.Regions) {
+			if rest >= 0 {
+				return nil, fmt.Errorf(`waves %d and %d select the rest of the regions ("*")`, rest+1, i+1)
+			}
+
+			rest = i
+		}
+
+		expanded, err := ExpandRegions(values, groups)
+		if err != nil {
+			return nil, fmt.Errorf("wave %d: %w", i+1, err)
+		}
+
+		for _, r := range expanded {
+			if !slices.Contains(regions, r) {
+				continue
+			}
+
+			if w, ok := assigned[r]; ok {
+				return nil, fmt.Errorf("region %s is in waves %d and %d", r, w+1, i+1)
+			}
+
+			assigned[r] = i
+			waves[i].Regions = append(waves[i].Regions, r)
+		}
+	}
+
+	var missing []string
+
+	for _, r := range regions {
+		if _, ok := assigned[r]; ok {
+			continue
+		}
+
+		if rest < 0 {
+			missing = append(missing, r)
+			continue
+		}
+
+		waves[rest].Regions = append(waves[rest].Regions, r)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("regions not in any wave: %s", strings.Join(missing, ", "))
+	}
+
+	return slices.DeleteFunc(waves, func(w layer.Wave) bool {
+		return len(w.Regions) == 0
+	}), nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+func TestResolveWaves(t *testing.T) {
+	groups := map[string][]string{"eu": {"eu-west-1", "eu-central-1"}}
+	regions := []string{"us-east-1", "eu-west-1", "eu-central-1", "sa-east-1"}
+
+	tests := []struct {
+		name     string
+		waves    []WaveConfig
+		expected []layer.Wave
+		err      string
+	}{
+		{
+			name: "canary, group and rest",
+			waves: []WaveConfig{
+				{Regions: []string{"us-east-1"}, BakeTime: "15m"},
+				{Regions: []string{"@eu", "ap-south-1"}, BakeTime: "5m"},
+				{Regions: []string{"*"}},
+			},
+			expected: []layer.Wave{
+				{Regions: []string{"us-east-1"}, BakeTime: 15 * time.Minute},
+				{Regions: []string{"eu-west-1", "eu-central-1"}, BakeTime: 5 * time.Minute},
+				{Regions: []string{"sa-east-1"}},
+			},
+		},
+		{
+			name: "waves without regions left out",
+			waves: []WaveConfig{
+				{Regions: []string{"ap-south-1"}},
+				{Regions: []string{"*"}},
+			},
+			expected: []layer.Wave{
+				{Regions: regions},
+			},
+		},
+		{
+			name: "no waves",
+			err:  "rollout has no waves",
+		},
+		{
+			name:  "invalid bake time",
+			waves: []WaveConfig{{Regions: []string{"*"}, BakeTime: "5 minutes"}},
+			err:   `invalid bake time "5 minutes" of wave 1`,
+		},
+		{
+			name:  "unknown region",
+			waves: []WaveConfig{{Regions: []string{"us-east-9"}}},
+			err:   `wave 1: unknown region "us-east-9"`,
+		},
+		{
+			name:  "region in two waves",
+			waves: []WaveConfig{{Regions: []string{"eu-west-1"}}, {Regions: []string{"@eu", "*"}}},
+			err:   "region eu-west-1 is in waves 1 and 2",
+		},
+		{
+			name:  "two rest waves",
+			waves: []WaveConfig{{Regions: []string{"*"}}, {Regions: []string{"*"}}},
+			err:   `waves 1 and 2 select the rest of the regions ("*")`,
+		},
+		{
+			name:  "regions not in any wave",
+			waves: []WaveConfig{{Regions: []string{"@eu"}}},
+			err:   "regions not in any wave: us-east-1, sa-east-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waves, err := ResolveWaves(RolloutConfig{Waves: tt.waves}, regions, groups)
+
+			if err != nil && (tt.err == "" || !strings.HasPrefix(err.Error(), tt.err)) {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if err == nil && tt.err != "" {
+				t.Errorf("expected error '%s', got nil", tt.err)
+			}
+
+			if !reflect.DeepEqual(waves, tt.expected) {
+				t.Errorf("expected waves %v, got %v", tt.expected, waves)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// functionUpdateTimeout is the maximum duration waited for the function update.
+const functionUpdateTimeout = 5 * time.Minute
+
+// SmokeTestConfig represents the smoke test of a layer in the configuration
+// file, invoking a test function of each region using the published version.
+type SmokeTestConfig struct {
+	// Function is the test function of the regions not in Functions.
+	Function string `json:"function"`
+
+	// Functions are the test functions by region.
+	Functions map[string]string `json:"functions"`
+
+	// Payload is the JSON payload of the invocation, defaults to {}.
+	Payload json.RawMessage `json:"payload"`
+
+	// Expect is the regular expression the response payload must match.
+	Expect string `json:"expect"`
+}
+
+// FunctionClient is the subset of the lambda API used by the smoke tests.
+type FunctionClient interface {
+	GetFunctionConfiguration(context.Context, *lambda.GetFunctionConfigurationInput, ...func(*lambda.Options)) (*lambda.GetFunctionConfigurationOutput, error)
+	UpdateFunctionConfiguration(context.Context, *lambda.UpdateFunctionConfigurationInput, ...func(*lambda.Options)) (*lambda.UpdateFunctionConfigurationOutput, error)
+	Invoke(context.Context, *lambda.InvokeInput, ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
+// SmokeTester updates the test function of the region to the published layer
+// version, invokes it and checks its response. The previous layers of the
+// function are restored when the test fails or the version is rolled back.
+type SmokeTester struct {
+	config    SmokeTestConfig
+	expect    *regexp.Regexp
+	client    FunctionClient
+	endpoints map[string]string
+
+	mu       sync.Mutex
+	previous map[string][]string
+}
+
+// NewSmokeTester creates the smoke tester of the configuration, using the
+// endpoints by region like the layer option WithEndpoints.
+func NewSmokeTester(c SmokeTestConfig, client FunctionClient, endpoints map[string]string) (*SmokeTester, error) {
+	if c.Function == "" && len(c.Functions) == 0 {
+		return nil, errors.New("smoke test has no functions")
+	}
+
+	for region := range c.Functions {
+		if _, err := ExpandRegions([]string{region}, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.Payload) > 0 && !json.Valid(c.Payload) {
+		return nil, errors.New("smoke test payload must be valid JSON")
+	}
+
+	s := &SmokeTester{config: c, client: client, endpoints: endpoints, previous: map[string][]string{}}
+
+	if c.Expect != "" {
+		expect, err := regexp.Compile(c.Expect)
+		if err != nil {
+			return nil, fmt.Errorf("invalid smoke test expect: %w", err)
+		}
+
+		s.expect = expect
+	}
+
+	return s, nil
+}
+
+// Test runs the smoke test of the version in its region, regions without a
+// test function are not tested.
+func (s *SmokeTester) Test(ctx context.Context, v *layer.Version) error {
+	name := cmp.Or(s.config.Functions[v.Region], s.config.Function)
+	if name == "" {
+		return nil
+	}
+
+	opt := s.withRegion(v.Region)
+
+	conf, err := s.client.GetFunctionConfiguration(ctx, &lambda.GetFunctionConfigurationInput{
+		FunctionName: aws.String(name),
+	}, opt)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve function %s: %w", name, err)
+	}
+
+	// an empty list removes the layers, while a nil list keeps them.
+	previous := make([]string, 0, len(conf.Layers))
+	layers := make([]string, 0, len(conf.Layers)+1)
+
+	for _, l := range conf.Layers {
+		arn := aws.ToString(l.Arn)
+		previous = append(previous, arn)
+
+		if layerArnOf(arn) != layerArnOf(v.Arn) {
+			layers = append(layers, arn)
+		}
+	}
+
+	if err := s.updateLayers(ctx, name, append(layers, v.Arn), opt); err != nil {
+		return err
+	}
+
+	if err := s.invoke(ctx, name, opt); err != nil {
+		// the function must not keep using a version failing the test.
+		if rerr := s.updateLayers(context.WithoutCancel(ctx), name, previous, opt); rerr != nil {
+			return errors.Join(err, rerr)
+		}
+
+		return err
+	}
+
+	s.mu.Lock()
+	s.previous[v.Arn] = previous
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Restore updates the test function of the version region back to the layers
+// it used before the version was tested, e.g. when the version is rolled back.
+// Versions not tested are ignored.
+func (s *SmokeTester) Restore(ctx context.Context, v *layer.Version) error {
+	s.mu.Lock()
+	previous, ok := s.previous[v.Arn]
+	delete(s.previous, v.Arn)
+	s.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	name := cmp.Or(s.config.Functions[v.Region], s.config.Function)
+
+	return s.updateLayers(ctx, name, previous, s.withRegion(v.Region))
+}
+
+// updateLayers updates the layers of the function, waiting for the update.
+func (s *SmokeTester) updateLayers(ctx context.Context, name string, layers []string, opt func(*lambda.Options)) error {
+	_, err := s.client.UpdateFunctionConfiguration(ctx, &lambda.UpdateFunctionConfigurationInput{
+		FunctionName: aws.String(name),
+		Layers:       layers,
+	}, opt)
+	if err != nil {
+		return fmt.Errorf("unable to update function %s: %w", name, err)
+	}
+
+	waiter := lambda.NewFunctionUpdatedWaiter(s.client, func(o *lambda.FunctionUpdatedWaiterOptions) {
+		o.ClientOptions = append(o.ClientOptions, opt)
+	})
+
+	err = waiter.Wait(ctx, &lambda.GetFunctionConfigurationInput{FunctionName: aws.String(name)}, functionUpdateTimeout)
+	if err != nil {
+		return fmt.Errorf("function %s update failed: %w", name, err)
+	}
+
+	return nil
+}
+
+// invoke invokes the function with the payload, checking its response.
+func (s *SmokeTester) invoke(ctx context.Context, name string, opt func(*lambda.Options)) error {
+	payload := []byte(s.config.Payload)
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+
+	out, err := s.client.Invoke(ctx, &lambda.InvokeInput{
+		FunctionName: aws.String(name),
+		Payload:      payload,
+	}, opt)
+	if err != nil {
+		return fmt.Errorf("unable to invoke function %s: %w", name, err)
+	}
+
+	if out.FunctionError != nil {
+		return fmt.Errorf("function %s failed (%s): %s", name, aws.ToString(out.FunctionError), out.Payload)
+	}
+
+	if s.expect != nil && !s.expect.Match(out.Payload) {
+		return fmt.Errorf("function %s response %s does not match %q", name, out.Payload, s.config.Expect)
+	}
+
+	return nil
+}
+
+// withRegion returns the option setting the region and its endpoint.
+func (s *SmokeTester) withRegion(region string) func(*lambda.Options) {
+	return func(o *lambda.Options) {
+		o.Region = region
+
+		if endpoint := cmp.Or(s.endpoints[region], s.endpoints[""]); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	}
+}
+
+// layerArnOf returns the layer arn of the layer version arn.
+func layerArnOf(arn string) string {
+	if a, err := layer.ParseArn(arn); err == nil {
+		return a.LayerArn()
+	}
+
+	return arn
+}
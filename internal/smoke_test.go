@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/faabiosr/lb/layer"
+	"github.com/faabiosr/lb/layer/layertest"
+)
+
+func TestNewSmokeTester(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SmokeTestConfig
+		err    string
+	}{
+		{
+			name:   "valid",
+			config: SmokeTestConfig{Function: "canary", Payload: json.RawMessage(`{"ping": true}`), Expect: "ok"},
+		},
+		{
+			name: "no functions",
+			err:  "smoke test has no functions",
+		},
+		{
+			name:   "unknown region",
+			config: SmokeTestConfig{Functions: map[string]string{"eu-west-9": "canary"}},
+			err:    `unknown region "eu-west-9"`,
+		},
+		{
+			name:   "invalid expect",
+			config: SmokeTestConfig{Function: "canary", Expect: "(ok"},
+			err:    "invalid smoke test expect:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSmokeTester(tt.config, nil, nil)
+
+			if err != nil && (tt.err == "" || !strings.HasPrefix(err.Error(), tt.err)) {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if err == nil && tt.err != "" {
+				t.Errorf("expected error '%s', got nil", tt.err)
+			}
+		})
+	}
+}
+
+func TestSmokeTest(t *testing.T) {
+	f := layertest.New()
+	defer f.Close()
+
+	const (
+		previous = "arn:aws:lambda:eu-west-1:123456789012:layer:my-layer:1"
+		other    = "arn:aws:lambda:eu-west-1:123456789012:layer:other:4"
+		current  = "arn:aws:lambda:eu-west-1:123456789012:layer:my-layer:2"
+	)
+
+	var received []string
+
+	f.Function("eu-west-1", "canary", func(payload []byte, layers []string) ([]byte, error) {
+		received = append(received, string(payload))
+
+		if !slices.Contains(layers, current) {
+			return nil, errors.New("layer not updated")
+		}
+
+		return []byte(`{"status": "ok"}`), nil
+	})
+
+	ctx := context.Background()
+	v := &layer.Version{Region: "eu-west-1", Number: 2, Arn: current}
+
+	s, err := NewSmokeTester(SmokeTestConfig{
+		Functions: map[string]string{"eu-west-1": "canary"},
+		Payload:   json.RawMessage(`{"ping":true}`),
+		Expect:    `"status": "ok"`,
+	}, f, nil)
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	// the function starts with the previous version of the layer.
+	_, err = f.UpdateFunctionConfiguration(ctx, &lambda.UpdateFunctionConfigurationInput{
+		FunctionName: aws.String("canary"),
+		Layers:       []string{previous, other},
+	}, func(o *lambda.Options) {
+		o.Region = "eu-west-1"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Test(ctx, v); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if layers := f.FunctionLayers("eu-west-1", "canary"); !slices.Equal(layers, []string{other, current}) {
+		t.Errorf("expected the layer version replaced, got %v", layers)
+	}
+
+	if !slices.Equal(received, []string{`{"ping":true}`}) {
+		t.Errorf("expected the payload received, got %v", received)
+	}
+
+	if err := s.Test(ctx, &layer.Version{Region: "us-east-1", Number: 2}); err != nil {
+		t.Errorf("expected regions without function not tested, got error %v", err)
+	}
+
+	s.expect = nil
+	s.config.Payload = nil
+
+	if err := s.Test(ctx, &layer.Version{Region: "eu-west-1", Number: 3, Arn: previous[:len(previous)-1] + "3"}); err == nil || !strings.Contains(err.Error(), "function canary failed (Unhandled)") {
+		t.Errorf("expected a function error, got %v", err)
+	}
+
+	if layers := f.FunctionLayers("eu-west-1", "canary"); !slices.Equal(layers, []string{other, current}) {
+		t.Errorf("expected the layers restored after the failure, got %v", layers)
+	}
+
+	if err := s.Restore(ctx, v); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if layers := f.FunctionLayers("eu-west-1", "canary"); !slices.Equal(layers, []string{previous, other}) {
+		t.Errorf("expected the layers restored after the rollback, got %v", layers)
+	}
+
+	if err := s.Restore(ctx, &layer.Version{Region: "us-east-1", Number: 2}); err != nil {
+		t.Errorf("expected versions not tested ignored, got error %v", err)
+	}
+
+	s, _ = NewSmokeTester(SmokeTestConfig{Function: "canary", Expect: "healthy"}, f, nil)
+
+	if err := s.Test(ctx, v); err == nil || !strings.Contains(err.Error(), `does not match "healthy"`) {
+		t.Errorf("expected a response mismatch, got %v", err)
+	}
+
+	if layers := f.FunctionLayers("eu-west-1", "canary"); !slices.Equal(layers, []string{previous, other}) {
+		t.Errorf("expected the layers restored after the mismatch, got %v", layers)
+	}
+}
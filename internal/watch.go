@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// reconcile status of a layer.
+const (
+	StatusBalanced   = "balanced"
+	StatusDrift      = "drift"
+	StatusBumped     = "bumped"
+	StatusNoVersions = "no-versions"
+	StatusFailed     = "failed"
+)
+
+// Target is a layer and the regions it must be balanced across.
+type Target struct {
+	Balancer *layer.Balancer
+	Regions  []string
+}
+
+// Watcher periodically verifies the targets, bumping them when drift is detected.
+type Watcher struct {
+	Targets  []Target
+	Interval time.Duration
+	Jitter   float64
+	Bump     bool
+	Logger   *slog.Logger
+
+	// ShutdownTimeout is how long a reconcile cycle in progress may run after
+	// the context is done, before it is canceled too.
+	ShutdownTimeout time.Duration
+}
+
+// Run reconciles the targets every interval until the context is done. A
+// reconcile cycle in progress is given the shutdown timeout to complete, so no
+// layer is left half bumped unless the cycle hangs.
+func (w *Watcher) Run(ctx context.Context) error {
+	for cycle := 1; ; cycle++ {
+		cycleCtx, cancel := w.cycleContext(ctx)
+		w.Reconcile(cycleCtx, cycle)
+		cancel()
+
+		wait := jitter(w.Interval, w.Jitter)
+		w.Logger.Debug("waiting next reconcile cycle", "wait", wait)
+
+		select {
+		case <-ctx.Done():
+			w.Logger.Info("watch stopped", "reason", context.Cause(ctx))
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// cycleContext returns the context of a reconcile cycle, which is canceled
+// once the shutdown timeout elapses after the parent context is done.
+func (w *Watcher) cycleContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	cycleCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.NewTimer(w.ShutdownTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			w.Logger.Warn("canceling reconcile cycle after shutdown timeout", "timeout", w.ShutdownTimeout)
+			cancel()
+		case <-cycleCtx.Done():
+		}
+	})
+
+	return cycleCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Reconcile runs a single reconcile cycle over all targets.
+func (w *Watcher) Reconcile(ctx context.Context, cycle int) map[string]int {
+	started := time.Now()
+	summary := map[string]int{}
+
+	for _, t := range w.Targets {
+		status := w.reconcile(ctx, t)
+		summary[status]++
+	}
+
+	w.Logger.Info(
+		"reconcile cycle",
+		"cycle", cycle,
+		"layers", len(w.Targets),
+		"duration", time.Since(started),
+		slog.Int(StatusBalanced, summary[StatusBalanced]),
+		slog.Int(StatusDrift, summary[StatusDrift]),
+		slog.Int(StatusBumped, summary[StatusBumped]),
+		slog.Int(StatusNoVersions, summary[StatusNoVersions]),
+		slog.Int(StatusFailed, summary[StatusFailed]),
+	)
+
+	return summary
+}
+
+// reconcile verifies the target, bumping it when enabled, and returns its status.
+func (w *Watcher) reconcile(ctx context.Context, t Target) string {
+	log := w.Logger.With("layer", t.Balancer.Layer().Name, "regions", t.Regions)
+
+	versions, err := t.Balancer.Verify(ctx, t.Regions)
+	latest := int64(0)
+
+	for _, v := range versions {
+		latest = max(latest, v.Number)
+	}
+
+	switch {
+	case err == nil:
+		log.Info("layer balanced", "version", latest)
+		return StatusBalanced
+	case errors.Is(err, layer.ErrNoVersions):
+		log.Warn("layer has no published versions")
+		return StatusNoVersions
+	case !errors.Is(err, layer.ErrDrift):
+		log.Error("failed to verify layer", "error", err)
+		return StatusFailed
+	case !w.Bump:
+		log.Warn("layer drift detected", "version", latest, "error", err)
+		return StatusDrift
+	}
+
+	plan, err := layer.NewPlan(versions)
+	if err != nil {
+		log.Error("failed to plan layer bump", "error", err)
+		return StatusFailed
+	}
+
+	log.Info("bumping layer", "version", plan.Source.Number, "source", plan.Source.Region)
+
+	if err := t.Balancer.Bump(ctx, plan); err != nil {
+		log.Error("failed to bump layer", "error", err)
+		return StatusFailed
+	}
+
+	log.Info("layer bumped", "version", plan.Source.Number)
+
+	return StatusBumped
+}
+
+// jitter randomizes the duration by up to the factor, in both directions.
+func jitter(d time.Duration, factor float64) time.Duration {
+	if factor <= 0 {
+		return d
+	}
+
+	delta := float64(d) * factor
+
+	return d + time.Duration(delta*(2*rand.Float64()-1))
+}
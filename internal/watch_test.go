@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// driftSvc returns a service where the region "us-east-1" is one version behind.
+func driftSvc() *mockSvc {
+	return &mockSvc{
+		ListLayerVersionsFn: func(opts ...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+			o := &lambda.Options{}
+			for _, fn := range opts {
+				fn(o)
+			}
+
+			version := int64(10)
+			if o.Region == "us-east-1" {
+				version = 9
+			}
+
+			return &lambda.ListLayerVersionsOutput{
+				LayerVersions: []types.LayerVersionsListItem{{Version: version}},
+			}, nil
+		},
+	}
+}
+
+func TestWatcherReconcile(t *testing.T) {
+	regions := []string{"us-east-1", "eu-west-1"}
+
+	tests := []struct {
+		name   string
+		svc    *mockSvc
+		bump   bool
+		status string
+	}{
+		{
+			name:   "balanced",
+			svc:    &mockSvc{},
+			status: StatusBalanced,
+		},
+		{
+			name:   "drift",
+			svc:    driftSvc(),
+			status: StatusDrift,
+		},
+		{
+			name:   "bumped",
+			svc:    driftSvc(),
+			bump:   true,
+			status: StatusBumped,
+		},
+		{
+			name: "no versions",
+			svc: &mockSvc{
+				ListLayerVersionsFn: func(...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+					return &lambda.ListLayerVersionsOutput{}, nil
+				},
+			},
+			status: StatusNoVersions,
+		},
+		{
+			name: "failed",
+			svc: &mockSvc{
+				ListLayerVersionsFn: func(...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+					return nil, errors.New("failure")
+				},
+			},
+			status: StatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			w := &Watcher{
+				Targets: []Target{{
+					Balancer: mockBalancer("my-layer", tt.svc),
+					Regions:  regions,
+				}},
+				Bump:   tt.bump,
+				Logger: slog.New(slog.NewJSONHandler(buf, nil)),
+			}
+
+			summary := w.Reconcile(context.Background(), 1)
+			if summary[tt.status] != 1 {
+				t.Errorf("expected status '%s', got summary %v", tt.status, summary)
+			}
+
+			if !strings.Contains(buf.String(), `"msg":"reconcile cycle"`) {
+				t.Errorf("expected reconcile cycle log, got %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := &Watcher{
+		Interval: time.Hour,
+		Logger:   slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+	}
+
+	if err := w.Run(ctx); err != nil {
+		t.Errorf("expected nil, got error %v", err)
+	}
+}
+
+func TestWatcherCycleContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	w := &Watcher{
+		ShutdownTimeout: 50 * time.Millisecond,
+		Logger:          slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+	}
+
+	cycleCtx, done := w.cycleContext(ctx)
+	defer done()
+
+	cancel()
+
+	if err := cycleCtx.Err(); err != nil {
+		t.Errorf("expected cycle running during the shutdown timeout, got error %v", err)
+	}
+
+	select {
+	case <-cycleCtx.Done():
+	case <-time.After(time.Second):
+		t.Error("expected cycle canceled after the shutdown timeout")
+	}
+}
+
+func TestJitter(t *testing.T) {
+	d := 10 * time.Second
+
+	if got := jitter(d, 0); got != d {
+		t.Errorf("expected '%s', got '%s'", d, got)
+	}
+
+	for range 100 {
+		if got := jitter(d, 0.1); got < 9*time.Second || got > 11*time.Second {
+			t.Errorf("expected jitter between 9s and 11s, got '%s'", got)
+		}
+	}
+}
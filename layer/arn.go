@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// Arn represents a lambda layer arn, with the version when set.
+type Arn struct {
+	Partition string
+	Region    string
+	Account   string
+	Name      string
+	Version   int64
+}
+
+// ParseArn parses a layer arn or a layer version arn, e.g.
+// "arn:aws:lambda:us-east-1:123456789012:layer:my-layer:3".
+func ParseArn(s string) (*Arn, error) {
+	parsed, err := arn.Parse(s)
+	if err != nil || parsed.Service != "lambda" {
+		return nil, fmt.Errorf("invalid layer arn %q", s)
+	}
+
+	parts := strings.Split(parsed.Resource, ":")
+	if parts[0] != "layer" || len(parts) < 2 || len(parts) > 3 || parts[1] == "" {
+		return nil, fmt.Errorf("invalid layer arn %q", s)
+	}
+
+	a := &Arn{
+		Partition: parsed.Partition,
+		Region:    parsed.Region,
+		Account:   parsed.AccountID,
+		Name:      parts[1],
+	}
+
+	if len(parts) == 3 {
+		a.Version, err = strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || a.Version <= 0 {
+			return nil, fmt.Errorf("invalid layer version in arn %q", s)
+		}
+	}
+
+	return a, nil
+}
+
+// LayerArn returns the arn of the layer, without the version.
+func (a *Arn) LayerArn() string {
+	return fmt.Sprintf("arn:%s:lambda:%s:%s:layer:%s", a.Partition, a.Region, a.Account, a.Name)
+}
+
+// VersionArn returns the arn of the layer version.
+func (a *Arn) VersionArn(version int64) string {
+	return fmt.Sprintf("%s:%d", a.LayerArn(), version)
+}
+
+// String returns the arn, with the version when set.
+func (a *Arn) String() string {
+	if a.Version == 0 {
+		return a.LayerArn()
+	}
+
+	return a.VersionArn(a.Version)
+}
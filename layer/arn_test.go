@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseArn(t *testing.T) {
+	tests := []struct {
+		name     string
+		arn      string
+		expected *Arn
+		err      string
+	}{
+		{
+			name: "layer version arn",
+			arn:  "arn:aws:lambda:us-east-1:123456789012:layer:vendor-layer:3",
+			expected: &Arn{
+				Partition: "aws",
+				Region:    "us-east-1",
+				Account:   "123456789012",
+				Name:      "vendor-layer",
+				Version:   3,
+			},
+		},
+		{
+			name: "layer arn",
+			arn:  "arn:aws:lambda:us-east-1:123456789012:layer:vendor-layer",
+			expected: &Arn{
+				Partition: "aws",
+				Region:    "us-east-1",
+				Account:   "123456789012",
+				Name:      "vendor-layer",
+			},
+		},
+		{
+			name: "layer name",
+			arn:  "vendor-layer",
+			err:  `invalid layer arn "vendor-layer"`,
+		},
+		{
+			name: "function arn",
+			arn:  "arn:aws:lambda:us-east-1:123456789012:function:my-function",
+			err:  `invalid layer arn "arn:aws:lambda:us-east-1:123456789012:function:my-function"`,
+		},
+		{
+			name: "invalid version",
+			arn:  "arn:aws:lambda:us-east-1:123456789012:layer:vendor-layer:latest",
+			err:  `invalid layer version in arn "arn:aws:lambda:us-east-1:123456789012:layer:vendor-layer:latest"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, actual := ParseArn(tt.arn)
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if tt.err != err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected arn %+v, got %+v", tt.expected, got)
+			}
+
+			if got != nil && got.String() != tt.arn {
+				t.Errorf("expected arn '%s', got '%s'", tt.arn, got)
+			}
+		})
+	}
+}
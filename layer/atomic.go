@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// BumpAtomic bumps the regions of the plan like Bump, but when any region
+// fails the versions published by the bump are deleted in every region, so
+// they return to their previous latest version. The failure is returned as a
+// RollbackError, wrapped by a PartialError when some versions could not be
+// deleted.
+func (b *Balancer) BumpAtomic(ctx context.Context, plan *Plan) error {
+	published, err := b.bump(ctx, plan)
+	if err == nil {
+		return nil
+	}
+
+	return b.rollback(ctx, published, err)
+}
+
+// rollback deletes the published versions after the failure, in the reverse
+// order they were published, returning the RollbackError of the failure
+// joined with the errors restoring their smoke tests and recording the
+// deleted versions.
+func (b *Balancer) rollback(ctx context.Context, published []*Version, err error) error {
+	var partial *PartialError
+	if errors.As(err, &partial) {
+		err = partial.Err
+	}
+
+	rerr := &RollbackError{Err: err}
+
+	// the rollback must complete even when the bump was canceled.
+	ctx = context.WithoutCancel(ctx)
+
+	var errs []error
+
+	for _, v := range slices.Backward(published) {
+		b.progress(Event{Region: v.Region, Stage: StageRollback, Version: v.Number})
+
+		// the smoke test function stops using the version before it is deleted.
+		if err := b.restoreSmoke(ctx, v); err != nil {
+			errs = append(errs, err)
+		}
+
+		started := time.Now()
+
+		if err := b.layer.DeleteVersion(ctx, v); err != nil {
+			rerr.Remaining = append(rerr.Remaining, v)
+			continue
+		}
+
+		rerr.RolledBack = append(rerr.RolledBack, v)
+
+		// the version is deleted even when recording it fails.
+		if err := b.recordDelete(v, started); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var result error = rerr
+	if len(rerr.Remaining) > 0 {
+		result = &PartialError{Changed: regionsOf(rerr.Remaining), Err: rerr}
+	}
+
+	if len(errs) == 0 {
+		return result
+	}
+
+	return errors.Join(append([]error{result}, errs...)...)
+}
+
+// regionsOf returns the distinct regions of the versions.
+func regionsOf(versions []*Version) []string {
+	var regions []string
+
+	for _, v := range versions {
+		if !slices.Contains(regions, v.Region) {
+			regions = append(regions, v.Region)
+		}
+	}
+
+	return regions
+}
+
+// recordDelete records the deletion of the published version.
+func (b *Balancer) recordDelete(v *Version, started time.Time) error {
+	return b.record(Mutation{
+		Action:   ActionDelete,
+		Layer:    b.layer.Name,
+		Target:   v,
+		Started:  started,
+		Finished: time.Now(),
+	})
+}
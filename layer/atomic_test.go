@@ -0,0 +1,238 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// failingRecorder fails to record every mutation.
+type failingRecorder struct{}
+
+func (failingRecorder) Record(Mutation) error {
+	return errors.New("journal failure")
+}
+
+// mockMutations records the mutations.
+type mockMutations []Mutation
+
+func (m *mockMutations) Record(mut Mutation) error {
+	*m = append(*m, mut)
+	return nil
+}
+
+func TestBalancerBumpAtomic(t *testing.T) {
+	plan := &Plan{
+		Source:   &Version{Number: 12, Region: "eu-west-1"},
+		Versions: []*Version{{Number: 10, Region: "us-east-1"}},
+	}
+
+	hc := &http.Client{Transport: &mockResponder{}}
+
+	// publishes the version 11 and fails to publish the version 12.
+	publish := func() func() (*lambda.PublishLayerVersionOutput, error) {
+		calls := 0
+
+		return func() (*lambda.PublishLayerVersionOutput, error) {
+			if calls++; calls > 1 {
+				return nil, errors.New("failure")
+			}
+
+			return &lambda.PublishLayerVersionOutput{Version: 11}, nil
+		}
+	}
+
+	t.Run("bump success", func(t *testing.T) {
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				ListLayerVersionsFn: sourceVersions,
+				DeleteLayerVersionFn: func(*lambda.DeleteLayerVersionInput) (*lambda.DeleteLayerVersionOutput, error) {
+					return nil, errors.New("unexpected delete")
+				},
+			}),
+			WithHTTPClient(hc),
+		)
+
+		if err := b.BumpAtomic(context.Background(), plan); err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		var (
+			deleted   []int64
+			mutations mockMutations
+		)
+
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				ListLayerVersionsFn:   sourceVersions,
+				PublishLayerVersionFn: publish(),
+				DeleteLayerVersionFn: func(in *lambda.DeleteLayerVersionInput) (*lambda.DeleteLayerVersionOutput, error) {
+					deleted = append(deleted, aws.ToInt64(in.VersionNumber))
+					return &lambda.DeleteLayerVersionOutput{}, nil
+				},
+			}),
+			WithHTTPClient(hc),
+			WithRecorder(&mutations),
+		)
+
+		err := b.BumpAtomic(context.Background(), plan)
+
+		var rollbackErr *RollbackError
+		if !errors.As(err, &rollbackErr) {
+			t.Fatalf("expected a rollback error, got %v", err)
+		}
+
+		var partialErr *PartialError
+		if errors.As(err, &partialErr) {
+			t.Errorf("expected no partial error, got %v", err)
+		}
+
+		expected := "us-east-1: failed to publish layer version: failure (rolled back: us-east-1 (11))"
+		if err.Error() != expected {
+			t.Errorf("Unexpected error: %s (expected %s)", err, expected)
+		}
+
+		if len(deleted) != 1 || deleted[0] != 11 {
+			t.Errorf("expected version '11' deleted, got %v", deleted)
+		}
+
+		if last := mutations[len(mutations)-1]; last.Action != ActionDelete || last.Target.Number != 11 {
+			t.Errorf("expected the delete of version '11' recorded, got %+v", last)
+		}
+	})
+
+	t.Run("smoke test restore", func(t *testing.T) {
+		var calls []string
+
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				ListLayerVersionsFn:   sourceVersions,
+				PublishLayerVersionFn: publish(),
+				DeleteLayerVersionFn: func(in *lambda.DeleteLayerVersionInput) (*lambda.DeleteLayerVersionOutput, error) {
+					calls = append(calls, fmt.Sprintf("delete %d", aws.ToInt64(in.VersionNumber)))
+					return &lambda.DeleteLayerVersionOutput{}, nil
+				},
+			}),
+			WithHTTPClient(hc),
+			WithSmokeRestore(func(_ context.Context, v *Version) error {
+				calls = append(calls, fmt.Sprintf("restore %d", v.Number))
+				return errors.New("function not found")
+			}),
+		)
+
+		err := b.BumpAtomic(context.Background(), plan)
+
+		var rollbackErr *RollbackError
+		if !errors.As(err, &rollbackErr) || len(rollbackErr.RolledBack) != 1 {
+			t.Fatalf("expected the version rolled back, got %v", err)
+		}
+
+		if !strings.Contains(err.Error(), "smoke test restore of version 11 failed: function not found") {
+			t.Errorf("expected the restore error joined, got %v", err)
+		}
+
+		expected := []string{"restore 11", "delete 11"}
+		if !slices.Equal(calls, expected) {
+			t.Errorf("expected calls %v, got %v", expected, calls)
+		}
+	})
+
+	t.Run("publish canceled in flight", func(t *testing.T) {
+		var deleted []int64
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		publish := publish()
+
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				ListLayerVersionsFn: sourceVersions,
+				PublishLayerVersionFn: func() (*lambda.PublishLayerVersionOutput, error) {
+					cancel()
+					return publish()
+				},
+				DeleteLayerVersionFn: func(in *lambda.DeleteLayerVersionInput) (*lambda.DeleteLayerVersionOutput, error) {
+					deleted = append(deleted, aws.ToInt64(in.VersionNumber))
+					return &lambda.DeleteLayerVersionOutput{}, nil
+				},
+			}),
+			WithHTTPClient(hc),
+		)
+
+		var rollbackErr *RollbackError
+		if err := b.BumpAtomic(ctx, plan); !errors.As(err, &rollbackErr) {
+			t.Fatalf("expected a rollback error, got %v", err)
+		}
+
+		if len(deleted) != 1 || deleted[0] != 11 {
+			t.Errorf("expected version '11' deleted, got %v", deleted)
+		}
+	})
+
+	t.Run("record failure", func(t *testing.T) {
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				ListLayerVersionsFn:   sourceVersions,
+				PublishLayerVersionFn: publish(),
+			}),
+			WithHTTPClient(hc),
+			WithRecorder(failingRecorder{}),
+		)
+
+		err := b.BumpAtomic(context.Background(), plan)
+
+		var rollbackErr *RollbackError
+		if !errors.As(err, &rollbackErr) || len(rollbackErr.RolledBack) != 1 || len(rollbackErr.Remaining) != 0 {
+			t.Fatalf("expected the version rolled back without remaining versions, got %v", err)
+		}
+
+		var partialErr *PartialError
+		if errors.As(err, &partialErr) {
+			t.Errorf("expected no partial error, got %v", err)
+		}
+
+		if !strings.HasSuffix(err.Error(), "\njournal failure") {
+			t.Errorf("expected the record failure returned, got %v", err)
+		}
+	})
+
+	t.Run("rollback failure", func(t *testing.T) {
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				ListLayerVersionsFn:   sourceVersions,
+				PublishLayerVersionFn: publish(),
+				DeleteLayerVersionFn: func(*lambda.DeleteLayerVersionInput) (*lambda.DeleteLayerVersionOutput, error) {
+					return nil, errors.New("failure")
+				},
+			}),
+			WithHTTPClient(hc),
+		)
+
+		err := b.BumpAtomic(context.Background(), plan)
+
+		var partialErr *PartialError
+		if !errors.As(err, &partialErr) || len(partialErr.Changed) != 1 {
+			t.Fatalf("expected a partial error, got %v", err)
+		}
+
+		var rollbackErr *RollbackError
+		if !errors.As(err, &rollbackErr) || len(rollbackErr.Remaining) != 1 {
+			t.Errorf("expected a rollback error with remaining versions, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,403 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// bump progress stages.
+const (
+	StageStart     = "start"
+	StageDownload  = "download"
+	StagePublish   = "publish"
+	StageSkip      = "skip"
+	StageComplete  = "complete"
+	StageRollback  = "rollback"
+	StageSmokeTest = "smoke_test"
+)
+
+// rollout progress stages, reported with the wave instead of the region.
+const (
+	StageWave        = "wave"
+	StageBake        = "bake"
+	StageHealthCheck = "health_check"
+)
+
+// recentVersions is the number of latest versions of the region compared with
+// the copied versions, so content already published is not published again.
+const recentVersions = 10
+
+// Event represents the progress of a layer operation in a region, the account
+// is only set when replicating into other accounts and the wave only for the
+// rollout stages.
+type Event struct {
+	Account string
+	Region  string
+	Wave    int
+	Stage   string
+	Version int64
+}
+
+// ProgressFunc receives the progress events, it must be safe for concurrent use.
+type ProgressFunc func(Event)
+
+// Plan holds the latest version of each region and the greatest version
+// across them, which is the source of the versions missing in other regions.
+type Plan struct {
+	Source   *Version
+	Versions []*Version
+}
+
+// Regions returns the regions of the plan.
+func (p *Plan) Regions() []string {
+	regions := make([]string, len(p.Versions))
+	for i, v := range p.Versions {
+		regions[i] = v.Region
+	}
+
+	return regions
+}
+
+// NewPlan creates the plan from the latest version of each region, returning
+// ErrNoRegions when there are no versions or ErrNoVersions when the layer was
+// never published.
+func NewPlan(versions []*Version) (*Plan, error) {
+	source, err := Greatest(versions)
+	if err != nil {
+		return nil, err
+	}
+
+	if source.Number == 0 {
+		return nil, ErrNoVersions
+	}
+
+	return &Plan{Source: source, Versions: versions}, nil
+}
+
+// Behind returns the latest version of the regions missing the content of the
+// source version.
+func (p *Plan) Behind() []*Version {
+	var behind []*Version
+
+	for _, v := range p.Versions {
+		if !SameContent(v, p.Source) {
+			behind = append(behind, v)
+		}
+	}
+
+	return behind
+}
+
+// Balancer balances the layer versions across regions.
+type Balancer struct {
+	layer        *Layer
+	progress     ProgressFunc
+	recorder     Recorder
+	smokeTest    SmokeTest
+	smokeRestore SmokeRestore
+	hooks        Hook
+}
+
+// NewBalancer creates the balancer of the layer, using a lambda client created
+// from the aws config unless another client is provided with WithClient.
+func NewBalancer(cfg aws.Config, name string, opts ...Option) *Balancer {
+	o := newOptions(opts)
+
+	return &Balancer{
+		layer:        o.layer(cfg, name),
+		progress:     o.progress,
+		recorder:     o.recorder,
+		smokeTest:    o.smokeTest,
+		smokeRestore: o.smokeRestore,
+		hooks:        o.hooks,
+	}
+}
+
+// Layer returns the balanced layer.
+func (b *Balancer) Layer() *Layer {
+	return b.layer
+}
+
+// Plan retrieves the latest version of each region with its code checksum,
+// returning ErrNoRegions without regions or ErrNoVersions when the layer was
+// never published.
+func (b *Balancer) Plan(ctx context.Context, regions []string) (*Plan, error) {
+	versions, err := b.layer.LatestVersions(ctx, regions)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPlan(versions)
+}
+
+// Verify verifies whether the layer latest version has the same content across
+// regions, returning the latest version of each region and the CheckBalance
+// error.
+func (b *Balancer) Verify(ctx context.Context, regions []string) ([]*Version, error) {
+	versions, err := b.layer.LatestVersions(ctx, regions)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, CheckBalance(versions)
+}
+
+// Bump copies the versions missing in each region of the plan from the source
+// region, so each region ends up with the same latest version. When a region
+// fails after other regions were changed, a PartialError is returned.
+func (b *Balancer) Bump(ctx context.Context, plan *Plan) error {
+	_, err := b.bump(ctx, plan)
+	return err
+}
+
+// bump bumps the regions of the plan, returning the published versions. A
+// failing region does not cancel the others, its error is joined with the
+// errors of the other failing regions.
+func (b *Balancer) bump(ctx context.Context, plan *Plan) ([]*Version, error) {
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		changed   []string
+		published []*Version
+		errs      []error
+	)
+
+	for _, region := range plan.Regions() {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			latest, versions, err := b.copyVersions(ctx, plan.Source, b.layer, "", region)
+			if err == nil && len(versions) > 0 {
+				err = b.smoke(ctx, versions[len(versions)-1])
+			}
+
+			if err == nil && len(versions) > 0 {
+				err = b.hook(ctx, HookPostBump, &HookContext{
+					Layer:  b.layer.Name,
+					Region: region,
+					Source: plan.Source,
+					Target: versions[len(versions)-1],
+				})
+			}
+
+			if len(versions) > 0 {
+				latest = versions[len(versions)-1].Number
+			}
+
+			if err == nil {
+				b.progress(Event{Region: region, Stage: StageComplete, Version: latest})
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if len(versions) > 0 {
+				changed = append(changed, region)
+				published = append(published, versions...)
+			}
+
+			if err != nil {
+				errs = append(errs, &RegionError{Region: region, Err: err})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		if len(changed) > 0 {
+			return published, &PartialError{Changed: changed, Err: err}
+		}
+
+		return published, err
+	}
+
+	return published, nil
+}
+
+// copyVersions copies the versions missing in the region of the destination
+// layer from the source, returning the latest version of the region before
+// copying and the published versions. Versions whose content is
+// identical to one of the region recent versions are skipped, e.g. after a
+// failed run.
+func (b *Balancer) copyVersions(ctx context.Context, source *Version, dst *Layer, account, region string) (int64, []*Version, error) {
+	b.progress(Event{Account: account, Region: region, Stage: StageStart})
+
+	latest, err := dst.LatestVersion(ctx, region)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var published []*Version
+
+	first := latest.Number + 1
+
+	// lambda never reuses the number of a deleted version, so a region rolled
+	// back by an atomic bump can be ahead in number while missing the content
+	// of the source, in which case only the source version is copied.
+	if latest.Number >= source.Number {
+		if latest.Number == 0 || source.CodeSha256 == "" {
+			return latest.Number, published, nil
+		}
+
+		current, err := dst.FetchVersion(ctx, latest.Number, region)
+		if err != nil {
+			return latest.Number, published, err
+		}
+
+		if SameContent(current, source) {
+			return latest.Number, published, nil
+		}
+
+		first = source.Number
+	}
+
+	checksums := map[string]bool{}
+
+	if latest.Number > 0 {
+		recent, err := dst.RecentVersions(ctx, region, recentVersions)
+		if err != nil {
+			return latest.Number, published, err
+		}
+
+		for _, v := range recent {
+			if v.CodeSha256 != "" {
+				checksums[v.CodeSha256] = true
+			}
+		}
+	}
+
+	numbers, err := b.sourceNumbers(ctx, source, first)
+	if err != nil {
+		return latest.Number, published, err
+	}
+
+	for _, n := range numbers {
+		current, err := b.layer.FetchVersion(ctx, n, source.Region)
+		if err != nil {
+			return latest.Number, published, err
+		}
+
+		if checksums[current.CodeSha256] {
+			b.progress(Event{Account: account, Region: region, Stage: StageSkip, Version: current.Number})
+			continue
+		}
+
+		pv, err := b.copyVersion(ctx, current, dst, account, region)
+		if pv != nil {
+			published = append(published, pv)
+		}
+
+		if err != nil {
+			return latest.Number, published, err
+		}
+	}
+
+	return latest.Number, published, nil
+}
+
+// sourceNumbers returns the numbers of the source region versions from first
+// up to the source version in ascending order, listing them as the versions
+// deleted by a rollback leave gaps.
+func (b *Balancer) sourceNumbers(ctx context.Context, source *Version, first int64) ([]int64, error) {
+	if first == source.Number {
+		return []int64{first}, nil
+	}
+
+	versions, err := b.layer.ListVersions(ctx, source.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	var numbers []int64
+
+	for _, v := range slices.Backward(versions) {
+		if v.Number >= first && v.Number <= source.Number {
+			numbers = append(numbers, v.Number)
+		}
+	}
+
+	return numbers, nil
+}
+
+// copyVersion downloads the version and publishes it into the region of the
+// destination layer, running the hooks around both, and returning the
+// published version.
+func (b *Balancer) copyVersion(ctx context.Context, current *Version, dst *Layer, account, region string) (*Version, error) {
+	hc := &HookContext{Layer: dst.Name, Account: account, Region: region, Source: current}
+
+	if err := b.hook(ctx, HookPreDownload, hc); err != nil {
+		return nil, err
+	}
+
+	b.progress(Event{Account: account, Region: region, Stage: StageDownload, Version: current.Number})
+
+	buf := &bytes.Buffer{}
+	if err := b.layer.DownloadVersion(ctx, current, buf); err != nil {
+		return nil, err
+	}
+
+	if b.hooks != nil {
+		artifact, err := writeArtifact(dst.Name, region, current.Number, buf.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(artifact) // nolint:errcheck
+
+		hc.Artifact = artifact
+	}
+
+	if err := b.hook(ctx, HookPostDownload, hc); err != nil {
+		return nil, err
+	}
+
+	if err := b.hook(ctx, HookPrePublish, hc); err != nil {
+		return nil, err
+	}
+
+	target := *current
+	target.Region = region
+	target.Content = &Content{File: buf.Bytes()}
+
+	b.progress(Event{Account: account, Region: region, Stage: StagePublish, Version: current.Number})
+
+	started := time.Now()
+
+	// a started publish completes even when another region fails, so the
+	// published version is recorded and can be rolled back.
+	pv, err := dst.PublishVersion(context.WithoutCancel(ctx), &target)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.record(Mutation{
+		Action:   ActionPublish,
+		Layer:    dst.Name,
+		Account:  account,
+		Source:   current,
+		Target:   pv,
+		Started:  started,
+		Finished: time.Now(),
+	})
+	if err != nil {
+		return pv, err
+	}
+
+	hc.Target = pv
+
+	return pv, b.hook(ctx, HookPostPublish, hc)
+}
@@ -0,0 +1,336 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+func TestBalancerPlan(t *testing.T) {
+	t.Run("no regions", func(t *testing.T) {
+		b := NewBalancer(aws.Config{}, "my-layer", WithClient(&mockSvc{}))
+
+		_, err := b.Plan(context.Background(), nil)
+		if !errors.Is(err, ErrNoRegions) {
+			t.Errorf("expected no regions error, got %v", err)
+		}
+	})
+
+	t.Run("no versions", func(t *testing.T) {
+		b := NewBalancer(aws.Config{}, "my-layer", WithClient(&mockSvc{
+			ListLayerVersionsFn: func(...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+				return &lambda.ListLayerVersionsOutput{}, nil
+			},
+		}))
+
+		_, err := b.Plan(context.Background(), []string{"us-east-1", "eu-west-1"})
+		if !errors.Is(err, ErrNoVersions) {
+			t.Errorf("expected no versions error, got %v", err)
+		}
+	})
+
+	t.Run("regions behind", func(t *testing.T) {
+		b := NewBalancer(aws.Config{}, "my-layer", WithClient(driftSvc()))
+
+		plan, err := b.Plan(context.Background(), []string{"us-east-1", "eu-west-1"})
+		if err != nil {
+			t.Fatalf("expected nil, got error %v", err)
+		}
+
+		if plan.Source.Region != "eu-west-1" || plan.Source.Number != 10 {
+			t.Errorf("expected source version '10' in 'eu-west-1', got '%d' in '%s'", plan.Source.Number, plan.Source.Region)
+		}
+
+		behind := plan.Behind()
+		if len(behind) != 1 || behind[0].Region != "us-east-1" {
+			t.Errorf("expected region 'us-east-1' behind, got %v", behind)
+		}
+	})
+}
+
+func TestBalancerVerify(t *testing.T) {
+	b := NewBalancer(aws.Config{}, "my-layer", WithClient(driftSvc()))
+
+	versions, err := b.Verify(context.Background(), []string{"us-east-1", "eu-west-1"})
+	if !errors.Is(err, ErrDrift) {
+		t.Errorf("expected drift error, got %v", err)
+	}
+
+	if total := len(versions); total != 2 {
+		t.Errorf("expected versions '2', got '%d'", total)
+	}
+}
+
+func TestBalancerBump(t *testing.T) {
+	plan := &Plan{
+		Source:   &Version{Number: 12, Region: "eu-west-1"},
+		Versions: []*Version{{Number: 10, Region: "us-east-1"}},
+	}
+
+	hc := &http.Client{Transport: &mockResponder{}}
+
+	t.Run("bump success", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			events []Event
+		)
+
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{ListLayerVersionsFn: sourceVersions}),
+			WithHTTPClient(hc),
+			WithProgress(func(e Event) {
+				mu.Lock()
+				events = append(events, e)
+				mu.Unlock()
+			}),
+		)
+
+		if err := b.Bump(context.Background(), plan); err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		if total := len(events); total != 6 {
+			t.Errorf("expected events '6', got '%d'", total)
+		}
+
+		if last := events[len(events)-1]; last.Stage != StageComplete {
+			t.Errorf("expected last stage '%s', got '%s'", StageComplete, last.Stage)
+		}
+	})
+
+	t.Run("identical content skipped", func(t *testing.T) {
+		var skipped []int64
+
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				ListLayerVersionsFn: sourceVersions,
+				GetLayerVersionFn: func() (*lambda.GetLayerVersionOutput, error) {
+					return &lambda.GetLayerVersionOutput{
+						Content: &types.LayerVersionContentOutput{CodeSha256: aws.String("sha")},
+					}, nil
+				},
+				PublishLayerVersionFn: func() (*lambda.PublishLayerVersionOutput, error) {
+					return nil, errors.New("unexpected publish")
+				},
+			}),
+			WithProgress(func(e Event) {
+				if e.Stage == StageSkip {
+					skipped = append(skipped, e.Version)
+				}
+			}),
+		)
+
+		if err := b.Bump(context.Background(), plan); err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		if total := len(skipped); total != 2 {
+			t.Errorf("expected skipped versions '2', got '%d'", total)
+		}
+	})
+
+	t.Run("smoke test failure", func(t *testing.T) {
+		var tested []int64
+
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				ListLayerVersionsFn: sourceVersions,
+				PublishLayerVersionFn: func() (*lambda.PublishLayerVersionOutput, error) {
+					return &lambda.PublishLayerVersionOutput{Version: 11}, nil
+				},
+			}),
+			WithHTTPClient(hc),
+			WithSmokeTest(func(_ context.Context, v *Version) error {
+				tested = append(tested, v.Number)
+				return errors.New("unexpected response")
+			}),
+		)
+
+		err := b.Bump(context.Background(), plan)
+
+		expected := "us-east-1: smoke test of version 11 failed: unexpected response (changed regions: us-east-1)"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Unexpected error: %v (expected %s)", err, expected)
+		}
+
+		if len(tested) != 1 {
+			t.Errorf("expected the latest published version tested once, got %v", tested)
+		}
+	})
+
+	t.Run("hooks", func(t *testing.T) {
+		var (
+			hooks     []string
+			artifacts []string
+		)
+
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{ListLayerVersionsFn: sourceVersions}),
+			WithHTTPClient(hc),
+			WithHooks(func(_ context.Context, hc *HookContext) error {
+				hooks = append(hooks, hc.Hook)
+
+				if hc.Hook == HookPostDownload {
+					if _, err := os.Stat(hc.Artifact); err != nil {
+						t.Errorf("expected the artifact in the post-download hook, got error %v", err)
+					}
+
+					artifacts = append(artifacts, hc.Artifact)
+				}
+
+				if hc.Hook == HookPostPublish && hc.Target == nil {
+					t.Error("expected the published version in the post-publish hook")
+				}
+
+				return nil
+			}),
+		)
+
+		if err := b.Bump(context.Background(), plan); err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		expected := slices.Concat(HookNames[:4], HookNames[:4], []string{HookPostBump})
+		if !slices.Equal(hooks, expected) {
+			t.Errorf("expected hooks %v, got %v", expected, hooks)
+		}
+
+		for _, artifact := range artifacts {
+			if _, err := os.Stat(artifact); !os.IsNotExist(err) {
+				t.Errorf("expected artifact %s removed, got %v", artifact, err)
+			}
+		}
+	})
+
+	t.Run("pre hook failure", func(t *testing.T) {
+		published := false
+
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				ListLayerVersionsFn: sourceVersions,
+				PublishLayerVersionFn: func() (*lambda.PublishLayerVersionOutput, error) {
+					published = true
+					return &lambda.PublishLayerVersionOutput{Version: 11}, nil
+				},
+			}),
+			WithHTTPClient(hc),
+			WithHooks(func(_ context.Context, hc *HookContext) error {
+				if hc.Hook == HookPrePublish {
+					return errors.New("artifact rejected")
+				}
+
+				return nil
+			}),
+		)
+
+		err := b.Bump(context.Background(), plan)
+
+		expected := "us-east-1: pre-publish hook failed: artifact rejected"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Unexpected error: %v (expected %s)", err, expected)
+		}
+
+		if published {
+			t.Error("expected no version published")
+		}
+	})
+
+	t.Run("pre hook failure in a region", func(t *testing.T) {
+		failed := make(chan struct{})
+
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				ListLayerVersionsFn: sourceVersions,
+				PublishLayerVersionFn: func() (*lambda.PublishLayerVersionOutput, error) {
+					return &lambda.PublishLayerVersionOutput{Version: 11}, nil
+				},
+			}),
+			WithHTTPClient(hc),
+			WithHooks(func(ctx context.Context, hc *HookContext) error {
+				switch {
+				case hc.Region == "us-east-1" && hc.Hook == HookPrePublish:
+					close(failed)
+					return errors.New("artifact rejected")
+				case hc.Region == "ap-south-1" && hc.Hook == HookPreDownload:
+					// the region keeps running after the other region failed.
+					<-failed
+					return ctx.Err()
+				}
+
+				return nil
+			}),
+		)
+
+		err := b.Bump(context.Background(), &Plan{
+			Source:   plan.Source,
+			Versions: []*Version{{Number: 10, Region: "us-east-1"}, {Number: 10, Region: "ap-south-1"}},
+		})
+
+		expected := "us-east-1: pre-publish hook failed: artifact rejected (changed regions: ap-south-1)"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Unexpected error: %v (expected %s)", err, expected)
+		}
+	})
+
+	t.Run("region failure", func(t *testing.T) {
+		b := NewBalancer(aws.Config{}, "my-layer", WithClient(&mockSvc{
+			ListLayerVersionsFn: func(...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+				return nil, errors.New("failure")
+			},
+		}))
+
+		err := b.Bump(context.Background(), plan)
+
+		var regionErr *RegionError
+		if !errors.As(err, &regionErr) || regionErr.Region != "us-east-1" {
+			t.Errorf("expected a region error, got %v", err)
+		}
+
+		var partialErr *PartialError
+		if errors.As(err, &partialErr) {
+			t.Errorf("expected no partial error, got %v", err)
+		}
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		calls := 0
+
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				ListLayerVersionsFn: sourceVersions,
+				PublishLayerVersionFn: func() (*lambda.PublishLayerVersionOutput, error) {
+					if calls++; calls > 1 {
+						return nil, errors.New("failure")
+					}
+
+					return &lambda.PublishLayerVersionOutput{}, nil
+				},
+			}),
+			WithHTTPClient(hc),
+		)
+
+		err := b.Bump(context.Background(), plan)
+
+		var partialErr *PartialError
+		if !errors.As(err, &partialErr) {
+			t.Fatalf("expected a partial error, got %v", err)
+		}
+
+		if total := len(partialErr.Changed); total != 1 {
+			t.Errorf("expected changed regions '1', got '%d'", total)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNoVersions indicates that the layer has no published versions in any region.
+	ErrNoVersions = errors.New("there are no published versions")
+
+	// ErrDrift indicates that the layer latest version differs across regions.
+	ErrDrift = errors.New("some regions are not bumped")
+
+	// ErrNoRegions indicates that there are no region versions to compare.
+	ErrNoRegions = errors.New("there are no regions")
+)
+
+// DriftError holds the regions latest versions when they are not balanced.
+type DriftError struct {
+	Versions []*Version
+}
+
+// Error returns the drift error message listing each region version.
+func (e *DriftError) Error() string {
+	regions := make([]string, len(e.Versions))
+	for i, v := range e.Versions {
+		regions[i] = fmt.Sprintf("%s (%d)", v.Region, v.Number)
+	}
+
+	return fmt.Sprintf("%s: %s", ErrDrift, strings.Join(regions, ", "))
+}
+
+// Is reports whether the target is the drift error.
+func (e *DriftError) Is(target error) bool {
+	return target == ErrDrift
+}
+
+// RegionError represents a failure in a specific region.
+type RegionError struct {
+	Region string
+	Err    error
+}
+
+// Error returns the error message prefixed by the region.
+func (e *RegionError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Region, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RegionError) Unwrap() error {
+	return e.Err
+}
+
+// AccountError represents a failure in a specific account.
+type AccountError struct {
+	Account string
+	Err     error
+}
+
+// Error returns the error message prefixed by the account.
+func (e *AccountError) Error() string {
+	return fmt.Sprintf("account %s: %v", e.Account, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError represents a failure after some regions were already changed.
+type PartialError struct {
+	Changed []string
+	Err     error
+}
+
+// Error returns the error message listing the changed regions.
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%v (changed regions: %s)", e.Err, strings.Join(e.Changed, ", "))
+}
+
+// Unwrap returns the underlying error.
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// RollbackError represents an atomic bump failure, after the versions
+// published by the bump were deleted.
+type RollbackError struct {
+	Err error
+
+	// RolledBack are the deleted versions.
+	RolledBack []*Version
+
+	// Remaining are the published versions that failed to be deleted.
+	Remaining []*Version
+}
+
+// Error returns the error message listing the rolled back versions.
+func (e *RollbackError) Error() string {
+	msg := fmt.Sprintf("%v (rolled back: %s)", e.Err, VersionList(e.RolledBack))
+	if len(e.Remaining) > 0 {
+		msg += fmt.Sprintf(" (failed to roll back: %s)", VersionList(e.Remaining))
+	}
+
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *RollbackError) Unwrap() error {
+	return e.Err
+}
+
+// VersionList returns the region and number of each version, or "none".
+func VersionList(versions []*Version) string {
+	if len(versions) == 0 {
+		return "none"
+	}
+
+	items := make([]string, len(versions))
+	for i, v := range versions {
+		items[i] = fmt.Sprintf("%s (%d)", v.Region, v.Number)
+	}
+
+	return strings.Join(items, ", ")
+}
+
+// CheckBalance verifies whether all versions have the same content, compared
+// by their code checksum when known or by their number otherwise, returning
+// ErrNoVersions when nothing was published or a DriftError otherwise.
+func CheckBalance(versions []*Version) error {
+	for _, v := range versions {
+		if !SameContent(v, versions[0]) {
+			return &DriftError{Versions: versions}
+		}
+	}
+
+	if len(versions) == 0 || versions[0].Number == 0 {
+		return ErrNoVersions
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckBalance(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []*Version
+		err      string
+	}{
+		{
+			name: "no versions",
+			versions: []*Version{
+				{Region: "us-east-1"},
+				{Region: "us-west-2"},
+			},
+			err: "there are no published versions",
+		},
+		{
+			name: "drift",
+			versions: []*Version{
+				{Region: "us-east-1", Number: 3},
+				{Region: "us-west-2", Number: 2},
+			},
+			err: "some regions are not bumped: us-east-1 (3), us-west-2 (2)",
+		},
+		{
+			name: "balanced",
+			versions: []*Version{
+				{Region: "us-east-1", Number: 3},
+				{Region: "us-west-2", Number: 3},
+			},
+		},
+		{
+			name: "same content",
+			versions: []*Version{
+				{Region: "us-east-1", Number: 3, CodeSha256: "sha"},
+				{Region: "us-west-2", Number: 2, CodeSha256: "sha"},
+			},
+		},
+		{
+			name: "different content",
+			versions: []*Version{
+				{Region: "us-east-1", Number: 3, CodeSha256: "sha"},
+				{Region: "us-west-2", Number: 3, CodeSha256: "other"},
+			},
+			err: "some regions are not bumped: us-east-1 (3), us-west-2 (3)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := CheckBalance(tt.versions)
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if tt.err != err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+		})
+	}
+
+	t.Run("drift is comparable", func(t *testing.T) {
+		err := CheckBalance([]*Version{{Number: 1}, {Number: 2}})
+		if !errors.Is(err, ErrDrift) {
+			t.Errorf("expected drift error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// hook points of the balancer.
+const (
+	HookPreDownload  = "pre-download"
+	HookPostDownload = "post-download"
+	HookPrePublish   = "pre-publish"
+	HookPostPublish  = "post-publish"
+	HookPostBump     = "post-bump"
+)
+
+// HookNames lists the hook points in the order they run.
+var HookNames = []string{HookPreDownload, HookPostDownload, HookPrePublish, HookPostPublish, HookPostBump}
+
+// HookContext describes the layer operation around which a hook runs.
+type HookContext struct {
+	Hook    string
+	Layer   string
+	Account string
+	Region  string
+
+	// Source is the version copied into the region.
+	Source *Version
+
+	// Target is the published version, only set after publishing.
+	Target *Version
+
+	// Artifact is the path of the downloaded content, only set after
+	// downloading and removed after publishing.
+	Artifact string
+}
+
+// Hook runs at the hook points of each copied version and bumped region, an
+// error fails the region. It must be safe for concurrent use.
+type Hook func(ctx context.Context, hc *HookContext) error
+
+// hook runs the hook at the point, when one is set.
+func (b *Balancer) hook(ctx context.Context, name string, hc *HookContext) error {
+	if b.hooks == nil {
+		return nil
+	}
+
+	hc.Hook = name
+
+	if err := b.hooks(ctx, hc); err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+
+	return nil
+}
+
+// writeArtifact writes the content of the version into a temporary file,
+// returning its path.
+func writeArtifact(name, region string, version int64, content []byte) (string, error) {
+	f, err := os.CreateTemp("", fmt.Sprintf("lb-%s-%s-%d-*.zip", name, region, version))
+	if err != nil {
+		return "", fmt.Errorf("failed to create artifact: %w", err)
+	}
+
+	if _, err := f.Write(content); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+
+		return "", fmt.Errorf("failed to write artifact: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write artifact: %w", err)
+	}
+
+	return f.Name(), nil
+}
@@ -0,0 +1,462 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+// Package layer balances the versions of AWS lambda layers across regions.
+package layer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// operations notified to the observer.
+const (
+	OpList     = "list"
+	OpFetch    = "fetch"
+	OpDownload = "download"
+	OpPublish  = "publish"
+	OpDelete   = "delete"
+)
+
+// Client is the subset of the lambda API used to manage layers.
+type Client interface {
+	GetLayerVersion(context.Context, *lambda.GetLayerVersionInput, ...func(*lambda.Options)) (*lambda.GetLayerVersionOutput, error)
+	ListLayerVersions(context.Context, *lambda.ListLayerVersionsInput, ...func(*lambda.Options)) (*lambda.ListLayerVersionsOutput, error)
+	PublishLayerVersion(context.Context, *lambda.PublishLayerVersionInput, ...func(*lambda.Options)) (*lambda.PublishLayerVersionOutput, error)
+	DeleteLayerVersion(context.Context, *lambda.DeleteLayerVersionInput, ...func(*lambda.Options)) (*lambda.DeleteLayerVersionOutput, error)
+	ListLayers(context.Context, *lambda.ListLayersInput, ...func(*lambda.Options)) (*lambda.ListLayersOutput, error)
+}
+
+// Observer is notified about the layer operations, e.g. for recording metrics.
+// It must be safe for concurrent use.
+type Observer interface {
+	// Versions is called with the latest version of each region.
+	Versions(layer string, versions []*Version)
+
+	// Operation is called after each operation in a region.
+	Operation(layer, region, op string, d time.Duration, err error)
+}
+
+// Layer represents a lambda layer.
+type Layer struct {
+	Name      string
+	client    Client
+	hc        *http.Client
+	observer  Observer
+	endpoints map[string]string
+	downloads map[string]string
+}
+
+// Content represents the lambda layer content stored.
+type Content struct {
+	File     []byte
+	Location string
+}
+
+// Version represents the lambda layer version.
+type Version struct {
+	Description   string
+	Number        int64
+	Region        string
+	Arn           string
+	CodeSha256    string
+	Content       *Content
+	Architectures []types.Architecture
+	Runtimes      []types.Runtime
+	License       string
+}
+
+// New creates the layer, using a lambda client created from the aws config
+// unless another client is provided with WithClient.
+func New(cfg aws.Config, name string, opts ...Option) *Layer {
+	return newOptions(opts).layer(cfg, name)
+}
+
+// withRegion is a helper option that sets Region on service requests, and the
+// region endpoint when defined.
+func (l *Layer) withRegion(r string) func(o *lambda.Options) {
+	return func(o *lambda.Options) {
+		o.Region = r
+
+		if endpoint := regionEndpoint(l.endpoints, r); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	}
+}
+
+// regionEndpoint returns the endpoint of the region, falling back to the
+// endpoint of all regions.
+func regionEndpoint(endpoints map[string]string, region string) string {
+	if endpoint, ok := endpoints[region]; ok {
+		return endpoint
+	}
+
+	return endpoints[""]
+}
+
+// location returns the content location of the version, pointing to the
+// region download endpoint when defined.
+func (l *Layer) location(v *Version) (string, error) {
+	endpoint := regionEndpoint(l.downloads, v.Region)
+	if endpoint == "" {
+		return v.Content.Location, nil
+	}
+
+	loc, err := url.Parse(v.Content.Location)
+	if err != nil {
+		return "", fmt.Errorf("invalid layer content location: %w", err)
+	}
+
+	ep, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	loc.Scheme = ep.Scheme
+	loc.Host = ep.Host
+
+	return loc.String(), nil
+}
+
+// observe notifies the observer about the operation.
+func (l *Layer) observe(region, op string, started time.Time, err error) {
+	if l.observer != nil {
+		l.observer.Operation(l.Name, region, op, time.Since(started), err)
+	}
+}
+
+// FetchVersion retrieves a version of lambda layer by region.
+func (l *Layer) FetchVersion(ctx context.Context, version int64, region string) (*Version, error) {
+	started := time.Now()
+
+	out, err := l.client.GetLayerVersion(ctx, &lambda.GetLayerVersionInput{
+		LayerName:     aws.String(l.Name),
+		VersionNumber: aws.Int64(version),
+	}, l.withRegion(region))
+
+	l.observe(region, OpFetch, started, err)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve layer version: %w", err)
+	}
+
+	return &Version{
+		Description: aws.ToString(out.Description),
+		Number:      out.Version,
+		Region:      region,
+		Arn:         aws.ToString(out.LayerVersionArn),
+		CodeSha256:  aws.ToString(out.Content.CodeSha256),
+		Content: &Content{
+			Location: aws.ToString(out.Content.Location),
+		},
+		Architectures: out.CompatibleArchitectures,
+		Runtimes:      out.CompatibleRuntimes,
+		License:       aws.ToString(out.LicenseInfo),
+	}, nil
+}
+
+// LatestVersion retrieves the latest version of a lambda layer by region.
+func (l *Layer) LatestVersion(ctx context.Context, region string) (*Version, error) {
+	started := time.Now()
+
+	out, err := l.client.ListLayerVersions(ctx, &lambda.ListLayerVersionsInput{
+		LayerName: aws.String(l.Name),
+		MaxItems:  aws.Int32(1),
+	}, l.withRegion(region))
+
+	l.observe(region, OpList, started, err)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to list layer versions: %w", err)
+	}
+
+	if len(out.LayerVersions) == 0 {
+		return &Version{Region: region}, nil
+	}
+
+	latest := out.LayerVersions[0]
+
+	return &Version{
+		Description:   aws.ToString(latest.Description),
+		Number:        latest.Version,
+		Region:        region,
+		Architectures: latest.CompatibleArchitectures,
+		Runtimes:      latest.CompatibleRuntimes,
+		License:       aws.ToString(latest.LicenseInfo),
+	}, nil
+}
+
+// RecentVersions returns up to limit latest versions of the region, including
+// their code checksum, in descending order.
+func (l *Layer) RecentVersions(ctx context.Context, region string, limit int32) ([]*Version, error) {
+	started := time.Now()
+
+	out, err := l.client.ListLayerVersions(ctx, &lambda.ListLayerVersionsInput{
+		LayerName: aws.String(l.Name),
+		MaxItems:  aws.Int32(limit),
+	}, l.withRegion(region))
+
+	l.observe(region, OpList, started, err)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to list layer versions: %w", err)
+	}
+
+	versions := make([]*Version, 0, len(out.LayerVersions))
+
+	for _, item := range out.LayerVersions {
+		v, err := l.FetchVersion(ctx, item.Version, region)
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, v)
+	}
+
+	return versions, nil
+}
+
+// ListVersions retrieves all versions of the lambda layer in the region, in
+// descending order.
+func (l *Layer) ListVersions(ctx context.Context, region string) ([]*Version, error) {
+	var versions []*Version
+
+	p := lambda.NewListLayerVersionsPaginator(l.client, &lambda.ListLayerVersionsInput{
+		LayerName: aws.String(l.Name),
+	})
+
+	for p.HasMorePages() {
+		started := time.Now()
+		out, err := p.NextPage(ctx, l.withRegion(region))
+
+		l.observe(region, OpList, started, err)
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to list layer versions: %w", err)
+		}
+
+		for _, item := range out.LayerVersions {
+			versions = append(versions, &Version{
+				Description:   aws.ToString(item.Description),
+				Number:        item.Version,
+				Region:        region,
+				Architectures: item.CompatibleArchitectures,
+				Runtimes:      item.CompatibleRuntimes,
+				License:       aws.ToString(item.LicenseInfo),
+			})
+		}
+	}
+
+	return versions, nil
+}
+
+// LatestVersions retrieves the latest version of all lambda layer regions,
+// with their code checksum so they are compared by content.
+func (l *Layer) LatestVersions(ctx context.Context, regions []string) ([]*Version, error) {
+	versions := make([]*Version, len(regions))
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	fn := func(index int, region string) func() error {
+		return func() error {
+			v, err := l.LatestVersion(gctx, region)
+			if err != nil {
+				return &RegionError{Region: region, Err: err}
+			}
+
+			versions[index] = v
+
+			return nil
+		}
+	}
+
+	for i, r := range regions {
+		g.Go(fn(i, r))
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("one of regions failed to retrieve the version: %w", err)
+	}
+
+	if err := l.Checksums(ctx, versions); err != nil {
+		return nil, err
+	}
+
+	if l.observer != nil {
+		l.observer.Versions(l.Name, versions)
+	}
+
+	return versions, nil
+}
+
+// Checksums retrieves the code checksum of the versions without one, so
+// regions whose versions were skipped are compared by content instead of by
+// number.
+func (l *Layer) Checksums(ctx context.Context, versions []*Version) error {
+	g, ctx := errgroup.WithContext(ctx)
+
+	for _, v := range versions {
+		if v.Number == 0 || v.CodeSha256 != "" {
+			continue
+		}
+
+		g.Go(func() error {
+			fetched, err := l.FetchVersion(ctx, v.Number, v.Region)
+			if err != nil {
+				return &RegionError{Region: v.Region, Err: err}
+			}
+
+			v.CodeSha256 = fetched.CodeSha256
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("one of regions failed to retrieve the version: %w", err)
+	}
+
+	return nil
+}
+
+// SameContent reports whether the versions have the same content, compared by
+// their code checksum when both are known, or by their number otherwise.
+func SameContent(a, b *Version) bool {
+	if a.CodeSha256 != "" && b.CodeSha256 != "" {
+		return a.CodeSha256 == b.CodeSha256
+	}
+
+	return a.Number == b.Number
+}
+
+// GreatestVersion retrieves the greatest version of the lambda layer across regions.
+func (l *Layer) GreatestVersion(ctx context.Context, regions []string) (*Version, error) {
+	versions, err := l.LatestVersions(ctx, regions)
+	if err != nil {
+		return nil, err
+	}
+
+	return Greatest(versions)
+}
+
+// Greatest returns the version with the greatest number, or ErrNoRegions when
+// there are no versions.
+func Greatest(versions []*Version) (*Version, error) {
+	if len(versions) == 0 {
+		return nil, ErrNoRegions
+	}
+
+	return slices.MaxFunc(versions, func(c, n *Version) int {
+		return cmp.Compare(c.Number, n.Number)
+	}), nil
+}
+
+// DownloadVersion downloads the lambda layer version by region.
+func (l *Layer) DownloadVersion(ctx context.Context, v *Version, w io.Writer) error {
+	started := time.Now()
+	err := l.download(ctx, v, w)
+
+	l.observe(v.Region, OpDownload, started, err)
+
+	return err
+}
+
+func (l *Layer) download(ctx context.Context, v *Version, w io.Writer) error {
+	location, err := l.location(v)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := l.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the layer content: %w", err)
+	}
+	defer res.Body.Close() // nolint:errcheck
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to retrieve the layer content: unexpected status %s", res.Status)
+	}
+
+	_, err = io.Copy(w, res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to download the layer version: %w", err)
+	}
+
+	return nil
+}
+
+// PublishVersion publishes a new lambda layer version, returning the published
+// version with the number, arn and code checksum assigned by the service.
+func (l *Layer) PublishVersion(ctx context.Context, v *Version) (*Version, error) {
+	if v == nil {
+		return nil, errors.New("version must not be nil")
+	}
+
+	started := time.Now()
+
+	out, err := l.client.PublishLayerVersion(ctx, &lambda.PublishLayerVersionInput{
+		Content: &types.LayerVersionContentInput{
+			ZipFile: v.Content.File,
+		},
+		LayerName:               aws.String(l.Name),
+		CompatibleArchitectures: v.Architectures,
+		CompatibleRuntimes:      v.Runtimes,
+		Description:             aws.String(v.Description),
+		LicenseInfo:             aws.String(v.License),
+	}, l.withRegion(v.Region))
+
+	l.observe(v.Region, OpPublish, started, err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish layer version: %w", err)
+	}
+
+	published := *v
+	published.Number = out.Version
+	published.Arn = aws.ToString(out.LayerVersionArn)
+	published.Content = &Content{}
+
+	if out.Content != nil {
+		published.CodeSha256 = aws.ToString(out.Content.CodeSha256)
+		published.Content.Location = aws.ToString(out.Content.Location)
+	}
+
+	return &published, nil
+}
+
+// DeleteVersion deletes the lambda layer version of the region.
+func (l *Layer) DeleteVersion(ctx context.Context, v *Version) error {
+	started := time.Now()
+
+	_, err := l.client.DeleteLayerVersion(ctx, &lambda.DeleteLayerVersionInput{
+		LayerName:     aws.String(l.Name),
+		VersionNumber: aws.Int64(v.Number),
+	}, l.withRegion(v.Region))
+
+	l.observe(v.Region, OpDelete, started, err)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete layer version: %w", err)
+	}
+
+	return nil
+}
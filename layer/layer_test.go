@@ -0,0 +1,363 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+func TestFetchVersion(t *testing.T) {
+	t.Run("response failure", func(t *testing.T) {
+		l := &Layer{
+			client: &mockSvc{
+				GetLayerVersionFn: func() (*lambda.GetLayerVersionOutput, error) {
+					return nil, errors.New("failure")
+				},
+			},
+		}
+
+		_, err := l.FetchVersion(context.Background(), 1, "us-east-1")
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		l := &Layer{
+			client: &mockSvc{},
+		}
+
+		v, err := l.FetchVersion(context.Background(), 1, "us-east-1")
+		if err != nil {
+			t.Errorf("expected a nil, got error %v", err)
+		}
+
+		if v == nil {
+			t.Error("expected a version, got nil")
+		}
+	})
+}
+
+func TestLatestVersion(t *testing.T) {
+	t.Run("no version found", func(t *testing.T) {
+		l := &Layer{
+			client: &mockSvc{
+				ListLayerVersionsFn: func(...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+					return &lambda.ListLayerVersionsOutput{}, nil
+				},
+			},
+		}
+
+		got, err := l.LatestVersion(context.Background(), "us-east-1")
+		if err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		if got.Number != 0 {
+			t.Errorf("expected version '0', got '%d'", got.Number)
+		}
+	})
+
+	t.Run("latest version", func(t *testing.T) {
+		l := &Layer{client: &mockSvc{}}
+
+		got, err := l.LatestVersion(context.Background(), "us-east-1")
+		if err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		if got.Number != 10 {
+			t.Errorf("expected version '10', got '%d'", got.Number)
+		}
+
+		if total := len(got.Architectures); total != 1 {
+			t.Errorf("expected architectures '1', got '%d'", total)
+		}
+
+		if total := len(got.Runtimes); total != 1 {
+			t.Errorf("expected runtimes '1', got '%d'", total)
+		}
+	})
+}
+
+func TestListVersions(t *testing.T) {
+	t.Run("response failure", func(t *testing.T) {
+		l := &Layer{client: &mockSvc{
+			ListLayerVersionsFn: func(...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+				return nil, errors.New("failure")
+			},
+		}}
+
+		if _, err := l.ListVersions(context.Background(), "us-east-1"); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("all pages", func(t *testing.T) {
+		calls := 0
+
+		l := &Layer{client: &mockSvc{
+			ListLayerVersionsFn: func(...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+				calls++
+
+				if calls == 1 {
+					return &lambda.ListLayerVersionsOutput{
+						LayerVersions: []types.LayerVersionsListItem{{Version: 3}, {Version: 2}},
+						NextMarker:    aws.String("2"),
+					}, nil
+				}
+
+				return &lambda.ListLayerVersionsOutput{
+					LayerVersions: []types.LayerVersionsListItem{{Version: 1}},
+				}, nil
+			},
+		}}
+
+		versions, err := l.ListVersions(context.Background(), "us-east-1")
+		if err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		if total := len(versions); total != 3 || versions[2].Number != 1 {
+			t.Errorf("expected versions '3', got '%d'", total)
+		}
+	})
+}
+
+func TestGreatestVersion(t *testing.T) {
+	t.Run("response failure", func(t *testing.T) {
+		l := &Layer{
+			client: &mockSvc{
+				ListLayerVersionsFn: func(...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+					return nil, errors.New("failure")
+				},
+			},
+		}
+
+		_, err := l.GreatestVersion(context.Background(), []string{"us-east-1"})
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("no regions", func(t *testing.T) {
+		l := &Layer{client: &mockSvc{}}
+
+		_, err := l.GreatestVersion(context.Background(), nil)
+		if !errors.Is(err, ErrNoRegions) {
+			t.Errorf("expected no regions error, got %v", err)
+		}
+	})
+
+	t.Run("latest version", func(t *testing.T) {
+		l := &Layer{client: &mockSvc{}}
+
+		got, err := l.GreatestVersion(context.Background(), []string{"us-east-1", "us-west-2"})
+		if err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		if got.Number != 10 {
+			t.Errorf("expected version '10', got '%d'", got.Number)
+		}
+	})
+}
+
+func TestDownloadVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		svcFn  func() (*lambda.GetLayerVersionOutput, error)
+		tripFn func() (*http.Response, error)
+		writer io.Writer
+		err    string
+	}{
+		{
+			name:   "download request failure",
+			writer: io.Discard,
+			tripFn: func() (*http.Response, error) {
+				return nil, errors.New("failed")
+			},
+			err: `failed to retrieve the layer content: Get "": failed`,
+		},
+		{
+			name:   "unexpected status",
+			writer: io.Discard,
+			tripFn: func() (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusForbidden,
+					Status:     "403 Forbidden",
+					Body:       http.NoBody,
+				}, nil
+			},
+			err: "failed to retrieve the layer content: unexpected status 403 Forbidden",
+		},
+		{
+			name:   "download failure",
+			writer: &mockWriter{},
+			err:    "failed to download the layer version: failed",
+		},
+		{
+			name:   "download success",
+			writer: io.Discard,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Layer{
+				client: &mockSvc{
+					GetLayerVersionFn: tt.svcFn,
+				},
+				hc: &http.Client{Transport: &mockResponder{tt.tripFn}},
+			}
+
+			v := &Version{
+				Number:  1,
+				Region:  "us-east-1",
+				Content: &Content{},
+			}
+
+			actual := l.DownloadVersion(context.Background(), v, tt.writer)
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if tt.err != err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if actual != nil {
+				t.SkipNow()
+			}
+		})
+	}
+}
+
+func TestLocation(t *testing.T) {
+	l := &Layer{
+		endpoints: map[string]string{
+			"": "https://vpce.example.com",
+		},
+		downloads: map[string]string{
+			"us-east-1": "http://localhost:4566",
+			"eu-west-1": "https://vpce.example.com",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		region   string
+		location string
+		expected string
+		err      string
+	}{
+		{
+			name:     "emulator endpoint",
+			region:   "us-east-1",
+			location: "https://awslambda-us-east-1-layers.s3.amazonaws.com/snapshots/layer?X-Amz-Signature=abc",
+			expected: "http://localhost:4566/snapshots/layer?X-Amz-Signature=abc",
+		},
+		{
+			name:     "region endpoint",
+			region:   "eu-west-1",
+			location: "https://awslambda-eu-west-1-layers.s3.amazonaws.com/snapshots/layer",
+			expected: "https://vpce.example.com/snapshots/layer",
+		},
+		{
+			name:     "no download endpoint",
+			region:   "sa-east-1",
+			location: "https://awslambda-sa-east-1-layers.s3.amazonaws.com/snapshots/layer?X-Amz-Signature=abc",
+			expected: "https://awslambda-sa-east-1-layers.s3.amazonaws.com/snapshots/layer?X-Amz-Signature=abc",
+		},
+		{
+			name:     "invalid location",
+			region:   "us-east-1",
+			location: "://invalid",
+			err:      `invalid layer content location: parse "://invalid": missing protocol scheme`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, actual := l.location(&Version{
+				Region:  tt.region,
+				Content: &Content{Location: tt.location},
+			})
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if tt.err != err {
+				t.Errorf("Unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if got != tt.expected {
+				t.Errorf("expected location '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPublishVersion(t *testing.T) {
+	t.Run("nil version", func(t *testing.T) {
+		l := &Layer{}
+
+		_, err := l.PublishVersion(context.Background(), nil)
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("response failure", func(t *testing.T) {
+		l := &Layer{
+			client: &mockSvc{
+				PublishLayerVersionFn: func() (*lambda.PublishLayerVersionOutput, error) {
+					return nil, errors.New("failure")
+				},
+			},
+		}
+
+		_, err := l.PublishVersion(context.Background(), &Version{Content: &Content{}})
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("publish success", func(t *testing.T) {
+		l := &Layer{
+			client: &mockSvc{
+				PublishLayerVersionFn: func() (*lambda.PublishLayerVersionOutput, error) {
+					return &lambda.PublishLayerVersionOutput{
+						Version:         4,
+						LayerVersionArn: aws.String("arn:aws:lambda:eu-west-1:123456789012:layer:my-layer:4"),
+						Content:         &types.LayerVersionContentOutput{CodeSha256: aws.String("sha")},
+					}, nil
+				},
+			},
+		}
+
+		v, err := l.PublishVersion(context.Background(), &Version{Number: 2, Region: "eu-west-1", Content: &Content{}})
+		if err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		if v.Number != 4 || v.CodeSha256 != "sha" || v.Region != "eu-west-1" {
+			t.Errorf("expected published version '4' with checksum, got %+v", v)
+		}
+	})
+}
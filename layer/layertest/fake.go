@@ -0,0 +1,475 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+// Package layertest provides an in-memory fake of the lambda layer APIs for
+// testing code built on top of the layer package.
+package layertest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+// AccountID is the account owning the fake layers.
+const AccountID = "123456789012"
+
+// operations that accept injected faults.
+const (
+	OpGetLayerVersion     = "GetLayerVersion"
+	OpListLayerVersions   = "ListLayerVersions"
+	OpPublishLayerVersion = "PublishLayerVersion"
+	OpDeleteLayerVersion  = "DeleteLayerVersion"
+	OpListLayers          = "ListLayers"
+	OpDownload            = "Download"
+)
+
+// Version is a layer version stored by the fake.
+type Version struct {
+	Number        int64
+	Description   string
+	License       string
+	Architectures []types.Architecture
+	Runtimes      []types.Runtime
+	Content       []byte
+	CodeSha256    string
+	CreatedDate   string
+}
+
+// fault is an error injected into an operation.
+type fault struct {
+	op     string
+	region string
+	err    error
+	times  int
+}
+
+// layerKey identifies a layer in a region.
+type layerKey struct {
+	region string
+	name   string
+}
+
+// Fake is an in-memory fake of the lambda layer APIs, storing the versions per
+// region and serving their content through an httptest server.
+type Fake struct {
+	mu        sync.Mutex
+	layers    map[layerKey][]*Version
+	counters  map[layerKey]int64
+	functions map[layerKey]*function
+	faults    []*fault
+	srv       *httptest.Server
+}
+
+var _ layer.Client = &Fake{}
+
+// New creates the fake, starting the server of the layers content. The fake
+// must be closed after use.
+func New() *Fake {
+	f := &Fake{
+		layers:    map[layerKey][]*Version{},
+		counters:  map[layerKey]int64{},
+		functions: map[layerKey]*function{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{region}/{name}/{version}", f.serveContent)
+	f.routes(mux)
+	f.functionRoutes(mux)
+
+	f.srv = httptest.NewServer(mux)
+
+	return f
+}
+
+// Close shuts down the server of the layers content.
+func (f *Fake) Close() {
+	f.srv.Close()
+}
+
+// HTTPClient returns the http client for downloading the layers content.
+func (f *Fake) HTTPClient() *http.Client {
+	return f.srv.Client()
+}
+
+// Options returns the layer options using the fake as lambda client and the
+// fake server client for downloads.
+func (f *Fake) Options() []layer.Option {
+	return []layer.Option{
+		layer.WithClient(f),
+		layer.WithHTTPClient(f.HTTPClient()),
+	}
+}
+
+// Publish stores a new version of the layer in the region, like the
+// PublishLayerVersion API, returning the version number.
+func (f *Fake) Publish(region, name string, v Version) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.publish(layerKey{region, name}, &v).Number
+}
+
+// Versions returns the versions of the layer in the region, in ascending order.
+func (f *Fake) Versions(region, name string) []Version {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	versions := make([]Version, 0, len(f.layers[layerKey{region, name}]))
+	for _, v := range f.layers[layerKey{region, name}] {
+		versions = append(versions, *v)
+	}
+
+	return versions
+}
+
+// Latest returns the latest version number of the layer in the region, or
+// zero when there are no versions.
+func (f *Fake) Latest(region, name string) int64 {
+	versions := f.Versions(region, name)
+	if len(versions) == 0 {
+		return 0
+	}
+
+	return versions[len(versions)-1].Number
+}
+
+// Fail injects the error into the operation in the region, an empty region
+// matches any region. The error is returned on the next times calls, or on
+// every call when times is zero or less.
+func (f *Fake) Fail(op, region string, err error, times int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults = append(f.faults, &fault{op: op, region: region, err: err, times: times})
+}
+
+// Reset removes all injected faults.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults = nil
+}
+
+// GetLayerVersion returns the layer version, with its content served by the fake server.
+func (f *Fake) GetLayerVersion(_ context.Context, in *lambda.GetLayerVersionInput, opts ...func(*lambda.Options)) (*lambda.GetLayerVersionOutput, error) {
+	region := regionOf(opts)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fault(OpGetLayerVersion, region); err != nil {
+		return nil, err
+	}
+
+	key := layerKey{region, aws.ToString(in.LayerName)}
+
+	v := f.find(key, aws.ToInt64(in.VersionNumber))
+	if v == nil {
+		return nil, notFound(key, aws.ToInt64(in.VersionNumber))
+	}
+
+	return &lambda.GetLayerVersionOutput{
+		CompatibleArchitectures: v.Architectures,
+		CompatibleRuntimes:      v.Runtimes,
+		Content:                 f.content(key, v),
+		CreatedDate:             aws.String(v.CreatedDate),
+		Description:             aws.String(v.Description),
+		LayerArn:                aws.String(layerArn(key)),
+		LayerVersionArn:         aws.String(versionArn(key, v.Number)),
+		LicenseInfo:             aws.String(v.License),
+		Version:                 v.Number,
+	}, nil
+}
+
+// ListLayerVersions lists the layer versions in descending order, honoring
+// MaxItems and Marker.
+func (f *Fake) ListLayerVersions(_ context.Context, in *lambda.ListLayerVersionsInput, opts ...func(*lambda.Options)) (*lambda.ListLayerVersionsOutput, error) {
+	region := regionOf(opts)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fault(OpListLayerVersions, region); err != nil {
+		return nil, err
+	}
+
+	key := layerKey{region, aws.ToString(in.LayerName)}
+
+	versions := slices.Clone(f.layers[key])
+	slices.Reverse(versions)
+
+	start := 0
+	if in.Marker != nil {
+		start, _ = strconv.Atoi(aws.ToString(in.Marker))
+		start = min(max(start, 0), len(versions))
+	}
+
+	end := len(versions)
+	if in.MaxItems != nil {
+		end = min(start+int(aws.ToInt32(in.MaxItems)), end)
+	}
+
+	out := &lambda.ListLayerVersionsOutput{}
+
+	for _, v := range versions[start:end] {
+		out.LayerVersions = append(out.LayerVersions, types.LayerVersionsListItem{
+			CompatibleArchitectures: v.Architectures,
+			CompatibleRuntimes:      v.Runtimes,
+			CreatedDate:             aws.String(v.CreatedDate),
+			Description:             aws.String(v.Description),
+			LayerVersionArn:         aws.String(versionArn(key, v.Number)),
+			LicenseInfo:             aws.String(v.License),
+			Version:                 v.Number,
+		})
+	}
+
+	if end < len(versions) {
+		out.NextMarker = aws.String(strconv.Itoa(end))
+	}
+
+	return out, nil
+}
+
+// PublishLayerVersion stores a new layer version with the next version number.
+func (f *Fake) PublishLayerVersion(_ context.Context, in *lambda.PublishLayerVersionInput, opts ...func(*lambda.Options)) (*lambda.PublishLayerVersionOutput, error) {
+	region := regionOf(opts)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fault(OpPublishLayerVersion, region); err != nil {
+		return nil, err
+	}
+
+	if in.Content == nil || in.Content.ZipFile == nil {
+		return nil, &types.InvalidParameterValueException{Message: aws.String("layer content must be a zip file")}
+	}
+
+	key := layerKey{region, aws.ToString(in.LayerName)}
+
+	v := f.publish(key, &Version{
+		Description:   aws.ToString(in.Description),
+		License:       aws.ToString(in.LicenseInfo),
+		Architectures: in.CompatibleArchitectures,
+		Runtimes:      in.CompatibleRuntimes,
+		Content:       in.Content.ZipFile,
+	})
+
+	return &lambda.PublishLayerVersionOutput{
+		CompatibleArchitectures: v.Architectures,
+		CompatibleRuntimes:      v.Runtimes,
+		Content:                 f.content(key, v),
+		CreatedDate:             aws.String(v.CreatedDate),
+		Description:             aws.String(v.Description),
+		LayerArn:                aws.String(layerArn(key)),
+		LayerVersionArn:         aws.String(versionArn(key, v.Number)),
+		LicenseInfo:             aws.String(v.License),
+		Version:                 v.Number,
+	}, nil
+}
+
+// DeleteLayerVersion removes the layer version, deleting a missing version
+// succeeds like in the lambda service.
+func (f *Fake) DeleteLayerVersion(_ context.Context, in *lambda.DeleteLayerVersionInput, opts ...func(*lambda.Options)) (*lambda.DeleteLayerVersionOutput, error) {
+	region := regionOf(opts)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fault(OpDeleteLayerVersion, region); err != nil {
+		return nil, err
+	}
+
+	key := layerKey{region, aws.ToString(in.LayerName)}
+
+	f.layers[key] = slices.DeleteFunc(f.layers[key], func(v *Version) bool {
+		return v.Number == aws.ToInt64(in.VersionNumber)
+	})
+
+	return &lambda.DeleteLayerVersionOutput{}, nil
+}
+
+// ListLayers lists the layers of the region with versions, sorted by name,
+// honoring MaxItems and Marker.
+func (f *Fake) ListLayers(_ context.Context, in *lambda.ListLayersInput, opts ...func(*lambda.Options)) (*lambda.ListLayersOutput, error) {
+	region := regionOf(opts)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fault(OpListLayers, region); err != nil {
+		return nil, err
+	}
+
+	var keys []layerKey
+
+	for key, versions := range f.layers {
+		if key.region == region && len(versions) > 0 {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b layerKey) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	start := 0
+	if in.Marker != nil {
+		start, _ = strconv.Atoi(aws.ToString(in.Marker))
+		start = min(max(start, 0), len(keys))
+	}
+
+	end := len(keys)
+	if in.MaxItems != nil {
+		end = min(start+int(aws.ToInt32(in.MaxItems)), end)
+	}
+
+	out := &lambda.ListLayersOutput{}
+
+	for _, key := range keys[start:end] {
+		versions := f.layers[key]
+		v := versions[len(versions)-1]
+
+		out.Layers = append(out.Layers, types.LayersListItem{
+			LayerArn:  aws.String(layerArn(key)),
+			LayerName: aws.String(key.name),
+			LatestMatchingVersion: &types.LayerVersionsListItem{
+				CompatibleArchitectures: v.Architectures,
+				CompatibleRuntimes:      v.Runtimes,
+				CreatedDate:             aws.String(v.CreatedDate),
+				Description:             aws.String(v.Description),
+				LayerVersionArn:         aws.String(versionArn(key, v.Number)),
+				LicenseInfo:             aws.String(v.License),
+				Version:                 v.Number,
+			},
+		})
+	}
+
+	if end < len(keys) {
+		out.NextMarker = aws.String(strconv.Itoa(end))
+	}
+
+	return out, nil
+}
+
+// publish stores the version with the next number, version numbers are never
+// reused, like in the lambda service.
+func (f *Fake) publish(key layerKey, v *Version) *Version {
+	f.counters[key]++
+
+	v.Number = f.counters[key]
+	v.Content = slices.Clone(v.Content)
+	v.CodeSha256 = checksum(v.Content)
+	v.CreatedDate = time.Now().UTC().Format("2006-01-02T15:04:05.000-0700")
+
+	f.layers[key] = append(f.layers[key], v)
+
+	return v
+}
+
+// find returns the layer version, or nil when not found.
+func (f *Fake) find(key layerKey, number int64) *Version {
+	for _, v := range f.layers[key] {
+		if v.Number == number {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// fault returns the error injected into the operation, if any.
+func (f *Fake) fault(op, region string) error {
+	for i, ft := range f.faults {
+		if ft.op != op || (ft.region != "" && ft.region != region) {
+			continue
+		}
+
+		if ft.times > 0 {
+			if ft.times--; ft.times == 0 {
+				f.faults = slices.Delete(f.faults, i, i+1)
+			}
+		}
+
+		return ft.err
+	}
+
+	return nil
+}
+
+// content returns the content output of the version.
+func (f *Fake) content(key layerKey, v *Version) *types.LayerVersionContentOutput {
+	return &types.LayerVersionContentOutput{
+		CodeSha256: aws.String(v.CodeSha256),
+		CodeSize:   int64(len(v.Content)),
+		Location:   aws.String(fmt.Sprintf("%s/%s/%s/%d", f.srv.URL, key.region, key.name, v.Number)),
+	}
+}
+
+// serveContent serves the layer version content, like the presigned locations.
+func (f *Fake) serveContent(w http.ResponseWriter, r *http.Request) {
+	key := layerKey{r.PathValue("region"), r.PathValue("name")}
+	number, _ := strconv.ParseInt(r.PathValue("version"), 10, 64)
+
+	f.mu.Lock()
+	err := f.fault(OpDownload, key.region)
+	v := f.find(key, number)
+	f.mu.Unlock()
+
+	switch {
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	case v == nil:
+		http.NotFound(w, r)
+	default:
+		_, _ = w.Write(v.Content)
+	}
+}
+
+// regionOf returns the region set by the request options.
+func regionOf(opts []func(*lambda.Options)) string {
+	o := &lambda.Options{}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	return o.Region
+}
+
+// checksum returns the base64 encoded SHA-256 of the content, like CodeSha256.
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func layerArn(key layerKey) string {
+	return fmt.Sprintf("arn:aws:lambda:%s:%s:layer:%s", key.region, AccountID, key.name)
+}
+
+func versionArn(key layerKey, number int64) string {
+	return fmt.Sprintf("%s:%d", layerArn(key), number)
+}
+
+func notFound(key layerKey, number int64) error {
+	return &types.ResourceNotFoundException{
+		Message: aws.String(fmt.Sprintf("layer version %s does not exist", versionArn(key, number))),
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/faabiosr/lb/layer"
+)
+
+func TestFakeBump(t *testing.T) {
+	f := New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", Version{Content: []byte("v1"), Description: "first"})
+	f.Publish("us-east-1", "my-layer", Version{Content: []byte("v2"), Description: "second"})
+	f.Publish("eu-west-1", "my-layer", Version{Content: []byte("v1"), Description: "first"})
+
+	ctx := context.Background()
+	regions := []string{"us-east-1", "eu-west-1"}
+
+	b := layer.NewBalancer(aws.Config{}, "my-layer", f.Options()...)
+
+	plan, err := b.Plan(ctx, regions)
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if err := b.Bump(ctx, plan); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	versions := f.Versions("eu-west-1", "my-layer")
+	if total := len(versions); total != 2 {
+		t.Fatalf("expected versions '2', got '%d'", total)
+	}
+
+	source := f.Versions("us-east-1", "my-layer")[1]
+	if got := versions[1]; got.CodeSha256 != source.CodeSha256 || got.Description != source.Description {
+		t.Errorf("expected version copied from source, got %+v", got)
+	}
+
+	if _, err := b.Verify(ctx, regions); err != nil {
+		t.Errorf("expected nil, got error %v", err)
+	}
+}
+
+func TestFakeFail(t *testing.T) {
+	f := New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", Version{Content: []byte("v1")})
+
+	ctx := context.Background()
+	regions := []string{"us-east-1", "eu-west-1"}
+	failure := errors.New("failure")
+
+	b := layer.NewBalancer(aws.Config{}, "my-layer", f.Options()...)
+
+	f.Fail(OpListLayerVersions, "eu-west-1", failure, 1)
+
+	if _, err := b.Plan(ctx, regions); !errors.Is(err, failure) {
+		t.Errorf("expected injected error, got %v", err)
+	}
+
+	plan, err := b.Plan(ctx, regions)
+	if err != nil {
+		t.Fatalf("expected nil after the fault, got error %v", err)
+	}
+
+	f.Fail(OpDownload, "", failure, 0)
+
+	if err := b.Bump(ctx, plan); err == nil {
+		t.Error("expected an error, got nil")
+	}
+
+	if latest := f.Latest("eu-west-1", "my-layer"); latest != 0 {
+		t.Errorf("expected no version published, got '%d'", latest)
+	}
+
+	f.Reset()
+
+	if err := b.Bump(ctx, plan); err != nil {
+		t.Errorf("expected nil, got error %v", err)
+	}
+
+	if latest := f.Latest("eu-west-1", "my-layer"); latest != 1 {
+		t.Errorf("expected version '1', got '%d'", latest)
+	}
+}
+
+func TestFakeDelete(t *testing.T) {
+	f := New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", Version{Content: []byte("v1")})
+	f.Publish("us-east-1", "my-layer", Version{Content: []byte("v2")})
+
+	l := layer.New(aws.Config{}, "my-layer", f.Options()...)
+
+	if err := l.DeleteVersion(context.Background(), &layer.Version{Region: "us-east-1", Number: 2}); err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if latest := f.Latest("us-east-1", "my-layer"); latest != 1 {
+		t.Errorf("expected latest version '1', got '%d'", latest)
+	}
+
+	if number := f.Publish("us-east-1", "my-layer", Version{Content: []byte("v3")}); number != 3 {
+		t.Errorf("expected version number '3' not reused, got '%d'", number)
+	}
+}
+
+func TestFakeListLayers(t *testing.T) {
+	f := New()
+	defer f.Close()
+
+	f.Publish("us-east-1", "my-layer", Version{Content: []byte("v1")})
+	f.Publish("us-east-1", "my-layer", Version{Content: []byte("v2")})
+	f.Publish("eu-west-1", "my-layer", Version{Content: []byte("v1")})
+	f.Publish("eu-west-1", "other-layer", Version{Content: []byte("v1")})
+
+	layers, err := layer.ListLayers(context.Background(), aws.Config{}, []string{"us-east-1", "eu-west-1"}, f.Options()...)
+	if err != nil {
+		t.Fatalf("expected nil, got error %v", err)
+	}
+
+	if total := len(layers); total != 2 {
+		t.Fatalf("expected layers '2', got '%d'", total)
+	}
+
+	if s := layers[0]; s.Name != "my-layer" || len(s.Versions) != 2 || s.Versions[0].Number != 2 || s.Versions[1].Number != 1 {
+		t.Errorf("unexpected layer %s with versions %v", s.Name, s.Versions)
+	}
+
+	if regions := layers[1].Regions(); len(regions) != 1 || regions[0] != "eu-west-1" {
+		t.Errorf("expected other-layer only in eu-west-1, got %v", regions)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layertest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// operations of the functions that accept injected faults.
+const (
+	OpUpdateFunctionConfiguration = "UpdateFunctionConfiguration"
+	OpInvoke                      = "Invoke"
+)
+
+// functionPrefix is the path prefix of the lambda function REST API.
+const functionPrefix = "/2015-03-31/functions/{name}"
+
+// Handler handles the invocations of a fake function, receiving the payload
+// and the layer version arns of the function. An error is returned as the
+// function error of the invocation.
+type Handler func(payload []byte, layers []string) ([]byte, error)
+
+// function is a lambda function stored by the fake.
+type function struct {
+	layers  []string
+	handler Handler
+}
+
+// Function creates the function in the region, invoked by the handler.
+func (f *Fake) Function(region, name string, h Handler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.functions[layerKey{region, name}] = &function{handler: h}
+}
+
+// FunctionLayers returns the layer version arns of the function in the region.
+func (f *Fake) FunctionLayers(region, name string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if fn, ok := f.functions[layerKey{region, name}]; ok {
+		return slices.Clone(fn.layers)
+	}
+
+	return nil
+}
+
+// GetFunctionConfiguration returns the function layers, the function updates
+// complete immediately.
+func (f *Fake) GetFunctionConfiguration(_ context.Context, in *lambda.GetFunctionConfigurationInput, opts ...func(*lambda.Options)) (*lambda.GetFunctionConfigurationOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := layerKey{regionOf(opts), aws.ToString(in.FunctionName)}
+
+	fn, ok := f.functions[key]
+	if !ok {
+		return nil, functionNotFound(key)
+	}
+
+	return f.configuration(key, fn), nil
+}
+
+// UpdateFunctionConfiguration replaces the function layers.
+func (f *Fake) UpdateFunctionConfiguration(_ context.Context, in *lambda.UpdateFunctionConfigurationInput, opts ...func(*lambda.Options)) (*lambda.UpdateFunctionConfigurationOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := layerKey{regionOf(opts), aws.ToString(in.FunctionName)}
+
+	if err := f.fault(OpUpdateFunctionConfiguration, key.region); err != nil {
+		return nil, err
+	}
+
+	fn, ok := f.functions[key]
+	if !ok {
+		return nil, functionNotFound(key)
+	}
+
+	if in.Layers != nil {
+		fn.layers = slices.Clone(in.Layers)
+	}
+
+	out := f.configuration(key, fn)
+
+	return &lambda.UpdateFunctionConfigurationOutput{
+		FunctionName:     out.FunctionName,
+		Layers:           out.Layers,
+		State:            out.State,
+		LastUpdateStatus: out.LastUpdateStatus,
+	}, nil
+}
+
+// Invoke invokes the function handler with the payload.
+func (f *Fake) Invoke(_ context.Context, in *lambda.InvokeInput, opts ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	key := layerKey{regionOf(opts), aws.ToString(in.FunctionName)}
+
+	f.mu.Lock()
+	err := f.fault(OpInvoke, key.region)
+	fn, ok := f.functions[key]
+
+	var layers []string
+	if ok {
+		layers = slices.Clone(fn.layers)
+	}
+	f.mu.Unlock()
+
+	switch {
+	case err != nil:
+		return nil, err
+	case !ok:
+		return nil, functionNotFound(key)
+	}
+
+	out := &lambda.InvokeOutput{StatusCode: http.StatusOK, ExecutedVersion: aws.String("$LATEST")}
+
+	payload, err := fn.handler(in.Payload, layers)
+	if err != nil {
+		payload, _ = json.Marshal(map[string]string{"errorMessage": err.Error(), "errorType": "Error"})
+		out.FunctionError = aws.String("Unhandled")
+	}
+
+	out.Payload = payload
+
+	return out, nil
+}
+
+// configuration returns the configuration of the function.
+func (f *Fake) configuration(key layerKey, fn *function) *lambda.GetFunctionConfigurationOutput {
+	layers := make([]types.Layer, len(fn.layers))
+	for i, arn := range fn.layers {
+		layers[i] = types.Layer{Arn: aws.String(arn)}
+	}
+
+	return &lambda.GetFunctionConfigurationOutput{
+		FunctionName:     aws.String(key.name),
+		FunctionArn:      aws.String(fmt.Sprintf("arn:aws:lambda:%s:%s:function:%s", key.region, AccountID, key.name)),
+		Layers:           layers,
+		State:            types.StateActive,
+		LastUpdateStatus: types.LastUpdateStatusSuccessful,
+	}
+}
+
+// functionRoutes registers the lambda function REST API handlers.
+func (f *Fake) functionRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+functionPrefix+"/configuration", f.serveGetConfiguration)
+	mux.HandleFunc("PUT "+functionPrefix+"/configuration", f.serveUpdateConfiguration)
+	mux.HandleFunc("POST "+functionPrefix+"/invocations", f.serveInvoke)
+}
+
+func (f *Fake) serveGetConfiguration(w http.ResponseWriter, r *http.Request) {
+	in := &lambda.GetFunctionConfigurationInput{FunctionName: aws.String(r.PathValue("name"))}
+
+	out, err := f.GetFunctionConfiguration(r.Context(), in, inRegion(r))
+	respond(w, http.StatusOK, out, err)
+}
+
+func (f *Fake) serveUpdateConfiguration(w http.ResponseWriter, r *http.Request) {
+	in := &lambda.UpdateFunctionConfigurationInput{}
+	if err := json.NewDecoder(r.Body).Decode(in); err != nil {
+		respond(w, 0, nil, &types.InvalidParameterValueException{Message: aws.String(err.Error())})
+		return
+	}
+
+	in.FunctionName = aws.String(r.PathValue("name"))
+
+	out, err := f.UpdateFunctionConfiguration(r.Context(), in, inRegion(r))
+	respond(w, http.StatusOK, out, err)
+}
+
+func (f *Fake) serveInvoke(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		respond(w, 0, nil, &types.InvalidParameterValueException{Message: aws.String(err.Error())})
+		return
+	}
+
+	out, err := f.Invoke(r.Context(), &lambda.InvokeInput{
+		FunctionName: aws.String(r.PathValue("name")),
+		Payload:      payload,
+	}, inRegion(r))
+	if err != nil {
+		respond(w, 0, nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Amz-Executed-Version", aws.ToString(out.ExecutedVersion))
+
+	if out.FunctionError != nil {
+		w.Header().Set("X-Amz-Function-Error", aws.ToString(out.FunctionError))
+	}
+
+	w.WriteHeader(int(out.StatusCode))
+	_, _ = w.Write(out.Payload)
+}
+
+func functionNotFound(key layerKey) error {
+	return &types.ResourceNotFoundException{
+		Message: aws.String(fmt.Sprintf("function not found: arn:aws:lambda:%s:%s:function:%s", key.region, AccountID, key.name)),
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layertest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go"
+)
+
+// path prefixes of the lambda layer REST API.
+const (
+	layersPrefix = "/2018-10-31/layers"
+	apiPrefix    = layersPrefix + "/{name}/versions"
+)
+
+// credentialScope extracts the region from the SigV4 credential scope.
+var credentialScope = regexp.MustCompile(`Credential=[^/]+/\d{8}/([^/]+)/`)
+
+// URL returns the base URL of the fake server, which serves the lambda layer
+// REST API, so it can be used as endpoint of a real lambda client. The region
+// of each request is taken from its signature.
+func (f *Fake) URL() string {
+	return f.srv.URL
+}
+
+// routes registers the lambda layer REST API handlers.
+func (f *Fake) routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+layersPrefix, f.serveListLayers)
+	mux.HandleFunc("GET "+apiPrefix, f.serveList)
+	mux.HandleFunc("POST "+apiPrefix, f.servePublish)
+	mux.HandleFunc("GET "+apiPrefix+"/{version}", f.serveGet)
+	mux.HandleFunc("DELETE "+apiPrefix+"/{version}", f.serveDelete)
+}
+
+func (f *Fake) serveList(w http.ResponseWriter, r *http.Request) {
+	in := &lambda.ListLayerVersionsInput{LayerName: aws.String(r.PathValue("name"))}
+
+	q := r.URL.Query()
+	if q.Has("Marker") {
+		in.Marker = aws.String(q.Get("Marker"))
+	}
+
+	if items, err := strconv.ParseInt(q.Get("MaxItems"), 10, 32); err == nil {
+		in.MaxItems = aws.Int32(int32(items))
+	}
+
+	out, err := f.ListLayerVersions(r.Context(), in, inRegion(r))
+	respond(w, http.StatusOK, out, err)
+}
+
+func (f *Fake) serveListLayers(w http.ResponseWriter, r *http.Request) {
+	in := &lambda.ListLayersInput{}
+
+	q := r.URL.Query()
+	if q.Has("Marker") {
+		in.Marker = aws.String(q.Get("Marker"))
+	}
+
+	if items, err := strconv.ParseInt(q.Get("MaxItems"), 10, 32); err == nil {
+		in.MaxItems = aws.Int32(int32(items))
+	}
+
+	out, err := f.ListLayers(r.Context(), in, inRegion(r))
+	respond(w, http.StatusOK, out, err)
+}
+
+func (f *Fake) serveGet(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.ParseInt(r.PathValue("version"), 10, 64)
+	if err != nil {
+		respond(w, 0, nil, &types.InvalidParameterValueException{Message: aws.String("invalid version number")})
+		return
+	}
+
+	out, err := f.GetLayerVersion(r.Context(), &lambda.GetLayerVersionInput{
+		LayerName:     aws.String(r.PathValue("name")),
+		VersionNumber: aws.Int64(number),
+	}, inRegion(r))
+	respond(w, http.StatusOK, out, err)
+}
+
+func (f *Fake) servePublish(w http.ResponseWriter, r *http.Request) {
+	in := &lambda.PublishLayerVersionInput{}
+	if err := json.NewDecoder(r.Body).Decode(in); err != nil {
+		respond(w, 0, nil, &types.InvalidParameterValueException{Message: aws.String(err.Error())})
+		return
+	}
+
+	in.LayerName = aws.String(r.PathValue("name"))
+
+	out, err := f.PublishLayerVersion(r.Context(), in, inRegion(r))
+	respond(w, http.StatusCreated, out, err)
+}
+
+func (f *Fake) serveDelete(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.ParseInt(r.PathValue("version"), 10, 64)
+	if err != nil {
+		respond(w, 0, nil, &types.InvalidParameterValueException{Message: aws.String("invalid version number")})
+		return
+	}
+
+	_, err = f.DeleteLayerVersion(r.Context(), &lambda.DeleteLayerVersionInput{
+		LayerName:     aws.String(r.PathValue("name")),
+		VersionNumber: aws.Int64(number),
+	}, inRegion(r))
+	respond(w, http.StatusNoContent, nil, err)
+}
+
+// inRegion returns the option setting the region of the request signature.
+func inRegion(r *http.Request) func(*lambda.Options) {
+	region := ""
+	if m := credentialScope.FindStringSubmatch(r.Header.Get("Authorization")); m != nil {
+		region = m[1]
+	}
+
+	return func(o *lambda.Options) {
+		o.Region = region
+	}
+}
+
+// requestID returns a random request id.
+func requestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// respond writes the output as JSON, or the error in the REST API error format.
+func respond(w http.ResponseWriter, status int, out any, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Amzn-Requestid", requestID())
+
+	if err == nil {
+		w.WriteHeader(status)
+
+		if out != nil {
+			_ = json.NewEncoder(w).Encode(out)
+		}
+
+		return
+	}
+
+	code := "ServiceException"
+	status = http.StatusInternalServerError
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code = apiErr.ErrorCode()
+
+		var notFound *types.ResourceNotFoundException
+		status = http.StatusBadRequest
+
+		if errors.As(err, &notFound) {
+			status = http.StatusNotFound
+		}
+	}
+
+	w.Header().Set("X-Amzn-Errortype", code)
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+}
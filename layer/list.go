@@ -0,0 +1,136 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"golang.org/x/sync/errgroup"
+)
+
+// Summary represents a lambda layer with its latest version in each region
+// where it has versions.
+type Summary struct {
+	Name string
+
+	// Versions are the latest versions, in the order of the listed regions.
+	Versions []*Version
+}
+
+// Regions returns the regions where the layer has versions.
+func (s *Summary) Regions() []string {
+	regions := make([]string, 0, len(s.Versions))
+	for _, v := range s.Versions {
+		regions = append(regions, v.Region)
+	}
+
+	return regions
+}
+
+// Latest returns the latest version of each region, regions where the layer
+// has no versions have the version number zero.
+func (s *Summary) Latest(regions []string) []*Version {
+	versions := make([]*Version, len(regions))
+
+	for i, region := range regions {
+		versions[i] = &Version{Region: region}
+
+		for _, v := range s.Versions {
+			if v.Region == region {
+				versions[i] = v
+			}
+		}
+	}
+
+	return versions
+}
+
+// ListLayers lists the lambda layers of the regions, joined by name and sorted
+// by name.
+func ListLayers(ctx context.Context, cfg aws.Config, regions []string, opts ...Option) ([]*Summary, error) {
+	l := newOptions(opts).layer(cfg, "")
+
+	listed := make([][]*Summary, len(regions))
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	fn := func(index int, region string) func() error {
+		return func() error {
+			summaries, err := l.listLayers(ctx, region)
+			if err != nil {
+				return &RegionError{Region: region, Err: err}
+			}
+
+			listed[index] = summaries
+
+			return nil
+		}
+	}
+
+	for i, r := range regions {
+		g.Go(fn(i, r))
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("one of regions failed to list the layers: %w", err)
+	}
+
+	layers := map[string]*Summary{}
+
+	for _, summaries := range listed {
+		for _, s := range summaries {
+			if _, ok := layers[s.Name]; !ok {
+				layers[s.Name] = &Summary{Name: s.Name}
+			}
+
+			layers[s.Name].Versions = append(layers[s.Name].Versions, s.Versions...)
+		}
+	}
+
+	return slices.SortedFunc(maps.Values(layers), func(a, b *Summary) int {
+		return cmp.Compare(a.Name, b.Name)
+	}), nil
+}
+
+// listLayers lists the lambda layers of the region with their latest version.
+func (l *Layer) listLayers(ctx context.Context, region string) ([]*Summary, error) {
+	var summaries []*Summary
+
+	p := lambda.NewListLayersPaginator(l.client, &lambda.ListLayersInput{})
+
+	for p.HasMorePages() {
+		out, err := p.NextPage(ctx, l.withRegion(region))
+		if err != nil {
+			return nil, fmt.Errorf("unable to list layers: %w", err)
+		}
+
+		for _, item := range out.Layers {
+			v := &Version{Region: region}
+
+			if latest := item.LatestMatchingVersion; latest != nil {
+				v.Description = aws.ToString(latest.Description)
+				v.Number = latest.Version
+				v.Arn = aws.ToString(latest.LayerVersionArn)
+				v.Architectures = latest.CompatibleArchitectures
+				v.Runtimes = latest.CompatibleRuntimes
+				v.License = aws.ToString(latest.LicenseInfo)
+			}
+
+			summaries = append(summaries, &Summary{
+				Name:     aws.ToString(item.LayerName),
+				Versions: []*Version{v},
+			})
+		}
+	}
+
+	return summaries, nil
+}
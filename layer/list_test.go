@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+func TestListLayers(t *testing.T) {
+	t.Run("joined by name", func(t *testing.T) {
+		svc := &mockSvc{
+			ListLayersFn: func(opts ...mockOpts) (*lambda.ListLayersOutput, error) {
+				o := &lambda.Options{}
+				for _, fn := range opts {
+					fn(o)
+				}
+
+				out := &lambda.ListLayersOutput{
+					Layers: []types.LayersListItem{
+						{LayerName: aws.String("b-layer"), LatestMatchingVersion: &types.LayerVersionsListItem{Version: 3}},
+					},
+				}
+
+				if o.Region == "eu-west-1" {
+					out.Layers = append(out.Layers, types.LayersListItem{
+						LayerName:             aws.String("a-layer"),
+						LatestMatchingVersion: &types.LayerVersionsListItem{Version: 1},
+					})
+				}
+
+				return out, nil
+			},
+		}
+
+		layers, err := ListLayers(context.Background(), aws.Config{}, []string{"us-east-1", "eu-west-1"}, WithClient(svc))
+		if err != nil {
+			t.Fatalf("expected nil, got error %v", err)
+		}
+
+		if total := len(layers); total != 2 {
+			t.Fatalf("expected layers '2', got '%d'", total)
+		}
+
+		if name := layers[0].Name; name != "a-layer" {
+			t.Errorf("expected first layer 'a-layer', got '%s'", name)
+		}
+
+		expected := []string{"us-east-1", "eu-west-1"}
+		if regions := layers[1].Regions(); !slices.Equal(regions, expected) {
+			t.Errorf("expected regions %v, got %v", expected, regions)
+		}
+
+		if v := layers[1].Versions[1]; v.Number != 3 || v.Region != "eu-west-1" {
+			t.Errorf("expected version 3 of eu-west-1, got %d of %s", v.Number, v.Region)
+		}
+
+		latest := layers[0].Latest([]string{"us-east-1", "eu-west-1"})
+		if latest[0].Number != 0 || latest[0].Region != "us-east-1" || latest[1].Number != 1 {
+			t.Errorf("expected versions 0 of us-east-1 and 1 of eu-west-1, got %v", latest)
+		}
+	})
+
+	t.Run("region failure", func(t *testing.T) {
+		svc := &mockSvc{
+			ListLayersFn: func(...mockOpts) (*lambda.ListLayersOutput, error) {
+				return nil, errors.New("failure")
+			},
+		}
+
+		_, err := ListLayers(context.Background(), aws.Config{}, []string{"us-east-1"}, WithClient(svc))
+
+		expected := "one of regions failed to list the layers: us-east-1: unable to list layers: failure"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Unexpected error: %v (expected %s)", err, expected)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// maxDescription is the maximum length of a layer version description.
+const maxDescription = 256
+
+// MirrorDescription returns the description of a mirrored version, recording
+// the upstream version arn and truncating the original description to fit.
+func MirrorDescription(desc, upstream string) string {
+	marker := mirrorMarker(upstream)
+
+	if room := maxDescription - len(marker) - 1; len(desc) > room {
+		desc = strings.TrimSpace(desc[:max(room, 0)])
+	}
+
+	if desc == "" {
+		return marker
+	}
+
+	return desc + " " + marker
+}
+
+// mirrorMarker returns the description part recording the upstream version arn.
+func mirrorMarker(upstream string) string {
+	return fmt.Sprintf("(mirror of %s)", upstream)
+}
+
+// Mirror copies the upstream versions, e.g. of a vendor layer owned by another
+// account, into each region of the balanced layer, keeping their metadata and
+// recording the upstream version arn in the description. The upstream layer
+// must be named by its arn, and the versions are downloaded and published one
+// at a time in ascending order, skipping the ones already mirrored into the
+// region. When a region fails after other regions were changed, a
+// PartialError is returned.
+func (b *Balancer) Mirror(ctx context.Context, upstream *Layer, versions []int64, regions []string) error {
+	src, err := ParseArn(upstream.Name)
+	if err != nil {
+		return err
+	}
+
+	versions = slices.Compact(slices.Sorted(slices.Values(versions)))
+
+	existing, err := b.mirrored(ctx, regions)
+	if err != nil {
+		return err
+	}
+
+	var changed []string
+
+	for _, n := range versions {
+		published, err := b.mirrorVersion(ctx, upstream, src, n, regions, existing)
+
+		for _, region := range published {
+			if !slices.Contains(changed, region) {
+				changed = append(changed, region)
+			}
+		}
+
+		if err != nil {
+			if len(changed) > 0 {
+				return &PartialError{Changed: changed, Err: err}
+			}
+
+			return err
+		}
+	}
+
+	var last int64
+	if len(versions) > 0 {
+		last = versions[len(versions)-1]
+	}
+
+	for _, region := range regions {
+		b.progress(Event{Region: region, Stage: StageComplete, Version: last})
+	}
+
+	return nil
+}
+
+// mirrored lists the versions of each region, so the versions already
+// mirrored are skipped.
+func (b *Balancer) mirrored(ctx context.Context, regions []string) (map[string][]*Version, error) {
+	g, ctx := errgroup.WithContext(ctx)
+
+	var mu sync.Mutex
+	existing := make(map[string][]*Version, len(regions))
+
+	for _, region := range regions {
+		g.Go(func() error {
+			b.progress(Event{Region: region, Stage: StageStart})
+
+			versions, err := b.layer.ListVersions(ctx, region)
+			if err != nil {
+				return &RegionError{Region: region, Err: err}
+			}
+
+			mu.Lock()
+			existing[region] = versions
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	return existing, g.Wait()
+}
+
+// mirrorVersion downloads the upstream version and publishes it into the
+// regions where it is not mirrored yet, returning the regions it was
+// published into.
+func (b *Balancer) mirrorVersion(ctx context.Context, upstream *Layer, src *Arn, n int64, regions []string, existing map[string][]*Version) ([]string, error) {
+	v, err := upstream.FetchVersion(ctx, n, src.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Description = MirrorDescription(v.Description, src.VersionArn(n))
+
+	var targets []string
+
+	for _, region := range regions {
+		mirrored := slices.ContainsFunc(existing[region], func(e *Version) bool {
+			return e.Description == v.Description
+		})
+
+		if mirrored {
+			b.progress(Event{Region: region, Stage: StageSkip, Version: n})
+			continue
+		}
+
+		targets = append(targets, region)
+	}
+
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	b.progress(Event{Region: src.Region, Stage: StageDownload, Version: n})
+
+	buf := &bytes.Buffer{}
+	if err := upstream.DownloadVersion(ctx, v, buf); err != nil {
+		return nil, err
+	}
+
+	v.Content.File = buf.Bytes()
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	var (
+		mu        sync.Mutex
+		published []string
+	)
+
+	for _, region := range targets {
+		g.Go(func() error {
+			pv, err := b.mirrorRegion(ctx, v, region)
+
+			if pv != nil {
+				mu.Lock()
+				published = append(published, region)
+				mu.Unlock()
+			}
+
+			if err != nil {
+				return &RegionError{Region: region, Err: err}
+			}
+
+			return nil
+		})
+	}
+
+	return published, g.Wait()
+}
+
+// mirrorRegion publishes the upstream version into the region, returning the
+// published version.
+func (b *Balancer) mirrorRegion(ctx context.Context, src *Version, region string) (*Version, error) {
+	b.progress(Event{Region: region, Stage: StagePublish, Version: src.Number})
+
+	v := *src
+	v.Region = region
+
+	started := time.Now()
+
+	pv, err := b.layer.PublishVersion(ctx, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return pv, b.record(Mutation{
+		Action:   ActionPublish,
+		Layer:    b.layer.Name,
+		Source:   src,
+		Target:   pv,
+		Started:  started,
+		Finished: time.Now(),
+	})
+}
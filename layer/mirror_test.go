@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+func TestMirrorDescription(t *testing.T) {
+	upstream := "arn:aws:lambda:us-east-1:123456789012:layer:vendor-layer:3"
+
+	if got := MirrorDescription("", upstream); got != "(mirror of "+upstream+")" {
+		t.Errorf("unexpected description '%s'", got)
+	}
+
+	if got := MirrorDescription("vendor layer", upstream); got != "vendor layer (mirror of "+upstream+")" {
+		t.Errorf("unexpected description '%s'", got)
+	}
+
+	got := MirrorDescription(strings.Repeat("a", maxDescription), upstream)
+	if len(got) != maxDescription || !strings.HasSuffix(got, "(mirror of "+upstream+")") {
+		t.Errorf("expected description truncated to '%d', got '%d' (%s)", maxDescription, len(got), got)
+	}
+}
+
+func TestBalancerMirror(t *testing.T) {
+	arn := "arn:aws:lambda:us-east-1:123456789012:layer:vendor-layer"
+	hc := &http.Client{Transport: &mockResponder{}}
+
+	upstream := New(aws.Config{}, arn, WithHTTPClient(hc), WithClient(&mockSvc{
+		GetLayerVersionFn: func() (*lambda.GetLayerVersionOutput, error) {
+			return &lambda.GetLayerVersionOutput{
+				Description: aws.String("vendor layer"),
+				Content:     &types.LayerVersionContentOutput{Location: aws.String("")},
+			}, nil
+		},
+	}))
+
+	var published atomic.Int32
+
+	b := NewBalancer(aws.Config{}, "my-layer", WithClient(&mockSvc{
+		ListLayerVersionsFn: func(opts ...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+			o := &lambda.Options{}
+			for _, fn := range opts {
+				fn(o)
+			}
+
+			out := &lambda.ListLayerVersionsOutput{}
+			if o.Region == "eu-west-1" {
+				out.LayerVersions = []types.LayerVersionsListItem{{
+					Version:     1,
+					Description: aws.String(MirrorDescription("vendor layer", arn+":1")),
+				}}
+			}
+
+			return out, nil
+		},
+		PublishLayerVersionFn: func() (*lambda.PublishLayerVersionOutput, error) {
+			published.Add(1)
+			return &lambda.PublishLayerVersionOutput{}, nil
+		},
+	}))
+
+	err := b.Mirror(context.Background(), upstream, []int64{2, 1, 2}, []string{"us-east-1", "eu-west-1"})
+	if err != nil {
+		t.Errorf("expected nil, got error %v", err)
+	}
+
+	if total := published.Load(); total != 3 {
+		t.Errorf("expected published versions '3', got '%d'", total)
+	}
+
+	err = b.Mirror(context.Background(), New(aws.Config{}, "vendor-layer"), []int64{1}, []string{"us-east-1"})
+	if err == nil {
+		t.Error("expected an error, got nil")
+	}
+}
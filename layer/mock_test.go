@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// Type alias to lambda options
+type mockOpts = func(*lambda.Options)
+
+type mockSvc struct {
+	GetLayerVersionFn     func() (*lambda.GetLayerVersionOutput, error)
+	ListLayerVersionsFn   func(...mockOpts) (*lambda.ListLayerVersionsOutput, error)
+	PublishLayerVersionFn func() (*lambda.PublishLayerVersionOutput, error)
+	DeleteLayerVersionFn  func(*lambda.DeleteLayerVersionInput) (*lambda.DeleteLayerVersionOutput, error)
+	ListLayersFn          func(...mockOpts) (*lambda.ListLayersOutput, error)
+}
+
+var _ Client = &mockSvc{}
+
+func (m *mockSvc) GetLayerVersion(context.Context, *lambda.GetLayerVersionInput, ...mockOpts) (*lambda.GetLayerVersionOutput, error) {
+	if m.GetLayerVersionFn != nil {
+		return m.GetLayerVersionFn()
+	}
+
+	return &lambda.GetLayerVersionOutput{
+		Content: &types.LayerVersionContentOutput{
+			Location: aws.String(""),
+		},
+	}, nil
+}
+
+func (m *mockSvc) ListLayerVersions(_ context.Context, _ *lambda.ListLayerVersionsInput, opts ...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+	if m.ListLayerVersionsFn != nil {
+		return m.ListLayerVersionsFn(opts...)
+	}
+
+	return &lambda.ListLayerVersionsOutput{
+		LayerVersions: []types.LayerVersionsListItem{
+			{
+				Version:                 10,
+				CompatibleArchitectures: []types.Architecture{types.ArchitectureX8664},
+				CompatibleRuntimes:      []types.Runtime{types.RuntimePython312},
+			},
+		},
+	}, nil
+}
+
+func (m *mockSvc) PublishLayerVersion(ctx context.Context, _ *lambda.PublishLayerVersionInput, _ ...mockOpts) (*lambda.PublishLayerVersionOutput, error) {
+	var (
+		out = &lambda.PublishLayerVersionOutput{}
+		err error
+	)
+
+	if m.PublishLayerVersionFn != nil {
+		out, err = m.PublishLayerVersionFn()
+	}
+
+	// the response of a request canceled in flight is lost.
+	if err == nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return out, err
+}
+
+func (m *mockSvc) DeleteLayerVersion(_ context.Context, in *lambda.DeleteLayerVersionInput, _ ...mockOpts) (*lambda.DeleteLayerVersionOutput, error) {
+	if m.DeleteLayerVersionFn != nil {
+		return m.DeleteLayerVersionFn(in)
+	}
+
+	return &lambda.DeleteLayerVersionOutput{}, nil
+}
+
+func (m *mockSvc) ListLayers(_ context.Context, _ *lambda.ListLayersInput, opts ...mockOpts) (*lambda.ListLayersOutput, error) {
+	if m.ListLayersFn != nil {
+		return m.ListLayersFn(opts...)
+	}
+
+	return &lambda.ListLayersOutput{}, nil
+}
+
+type mockWriter struct{}
+
+var _ io.Writer = &mockWriter{}
+
+func (w *mockWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("failed")
+}
+
+type mockResponder struct {
+	TripFn func() (*http.Response, error)
+}
+
+var _ http.RoundTripper = &mockResponder{}
+
+func (m *mockResponder) RoundTrip(r *http.Request) (*http.Response, error) {
+	if m.TripFn != nil {
+		return m.TripFn()
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("content")),
+	}, nil
+}
+
+// sourceVersions lists the versions 12, 11 and 10 in the region "eu-west-1",
+// the source region of the bump tests, and the version 10 in other regions.
+func sourceVersions(opts ...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+	o := &lambda.Options{}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	versions := []int64{10}
+	if o.Region == "eu-west-1" {
+		versions = []int64{12, 11, 10}
+	}
+
+	out := &lambda.ListLayerVersionsOutput{}
+	for _, v := range versions {
+		out.LayerVersions = append(out.LayerVersions, types.LayerVersionsListItem{Version: v})
+	}
+
+	return out, nil
+}
+
+// driftSvc returns a service where the region "us-east-1" is one version behind.
+func driftSvc() *mockSvc {
+	return &mockSvc{
+		ListLayerVersionsFn: func(opts ...mockOpts) (*lambda.ListLayerVersionsOutput, error) {
+			o := &lambda.Options{}
+			for _, fn := range opts {
+				fn(o)
+			}
+
+			version := int64(10)
+			if o.Region == "us-east-1" {
+				version = 9
+			}
+
+			return &lambda.ListLayerVersionsOutput{
+				LayerVersions: []types.LayerVersionsListItem{{Version: version}},
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// Option configures the layer and the balancer.
+type Option func(*options)
+
+type options struct {
+	client        Client
+	hc            *http.Client
+	observer      Observer
+	progress      ProgressFunc
+	recorder      Recorder
+	smokeTest     SmokeTest
+	smokeRestore  SmokeRestore
+	hooks         Hook
+	endpoints     map[string]string
+	downloads     map[string]string
+	lambdaOptions []func(*lambda.Options)
+}
+
+// WithClient sets the lambda client, e.g. a fake client for testing.
+func WithClient(c Client) Option {
+	return func(o *options) {
+		o.client = c
+	}
+}
+
+// WithHTTPClient sets the http client used for downloading the layer content.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) {
+		o.hc = hc
+	}
+}
+
+// WithObserver sets the observer notified about the layer operations.
+func WithObserver(obs Observer) Option {
+	return func(o *options) {
+		o.observer = obs
+	}
+}
+
+// WithProgress sets the function receiving the balancer progress events.
+func WithProgress(fn ProgressFunc) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+// WithRecorder sets the recorder of the layer mutations made by the balancer.
+func WithRecorder(r Recorder) Option {
+	return func(o *options) {
+		o.recorder = r
+	}
+}
+
+// WithHooks sets the hook run around the layer operations of the balancer.
+func WithHooks(h Hook) Option {
+	return func(o *options) {
+		o.hooks = h
+	}
+}
+
+// WithSmokeTest sets the smoke test of the latest version published by the
+// balancer in each bumped region.
+func WithSmokeTest(fn SmokeTest) Option {
+	return func(o *options) {
+		o.smokeTest = fn
+	}
+}
+
+// WithSmokeRestore sets the function reverting the smoke test of the versions
+// rolled back by the balancer.
+func WithSmokeRestore(fn SmokeRestore) Option {
+	return func(o *options) {
+		o.smokeRestore = fn
+	}
+}
+
+// WithEndpoints sets the lambda endpoint of each region, e.g. a local emulator
+// or a VPC endpoint, where the empty region sets the endpoint of all regions.
+func WithEndpoints(endpoints map[string]string) Option {
+	return func(o *options) {
+		o.endpoints = endpoints
+	}
+}
+
+// WithDownloadEndpoints sets the endpoint the layers content is downloaded
+// from in each region, replacing the scheme and host of the content location,
+// where the empty region sets the endpoint of all regions. It is meant for
+// emulators whose content locations are not reachable, since the signature of
+// the presigned S3 locations does not allow another host.
+func WithDownloadEndpoints(endpoints map[string]string) Option {
+	return func(o *options) {
+		o.downloads = endpoints
+	}
+}
+
+// WithLambdaOptions sets options of the lambda client created from the aws config.
+func WithLambdaOptions(fns ...func(*lambda.Options)) Option {
+	return func(o *options) {
+		o.lambdaOptions = append(o.lambdaOptions, fns...)
+	}
+}
+
+// newOptions applies the options over the default values.
+func newOptions(opts []Option) *options {
+	o := &options{
+		hc:       http.DefaultClient,
+		progress: func(Event) {},
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// layer creates the layer from the options.
+func (o *options) layer(cfg aws.Config, name string) *Layer {
+	client := o.client
+	if client == nil {
+		client = lambda.NewFromConfig(cfg, o.lambdaOptions...)
+	}
+
+	return &Layer{
+		Name:      name,
+		client:    client,
+		hc:        o.hc,
+		observer:  o.observer,
+		endpoints: o.endpoints,
+		downloads: o.downloads,
+	}
+}
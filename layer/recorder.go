@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"time"
+)
+
+// mutation actions recorded by the balancer.
+const (
+	ActionPublish = "publish"
+	ActionDelete  = "delete"
+)
+
+// Mutation represents a change of a layer version in a region.
+type Mutation struct {
+	Action string
+	Layer  string
+
+	// Account is the target account, only set when replicating into other accounts.
+	Account string
+
+	// Source is the version copied into the target, nil for deletes.
+	Source *Version
+
+	// Target is the version published or deleted.
+	Target *Version
+
+	Started  time.Time
+	Finished time.Time
+}
+
+// Recorder records the layer mutations, e.g. into an audit journal. It must
+// be safe for concurrent use, and a failure to record fails the operation.
+type Recorder interface {
+	Record(Mutation) error
+}
+
+// record records the mutation when a recorder is set.
+func (b *Balancer) record(m Mutation) error {
+	if b.recorder == nil {
+		return nil
+	}
+
+	return b.recorder.Record(m)
+}
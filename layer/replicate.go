@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Target is a region of another account receiving copies of the layer versions.
+type Target struct {
+	// Account identifies the account, e.g. the account id.
+	Account string
+
+	// Region is the region of the account.
+	Region string
+
+	// Layer is the layer in the account, created with the account credentials.
+	Layer *Layer
+}
+
+// Replication is the result of replicating the layer into a target.
+type Replication struct {
+	Account string
+	Region  string
+
+	// Previous is the latest version of the target before replicating.
+	Previous int64
+
+	// Published is the number of versions published into the target.
+	Published int
+
+	// Latest is the number of the last version published into the target,
+	// which may differ from the source number, zero when none was published.
+	Latest int64
+
+	// Err is the failure of the target, if any.
+	Err error
+}
+
+// Version returns the latest version of the target after replicating.
+func (r *Replication) Version() int64 {
+	if r.Latest > 0 {
+		return r.Latest
+	}
+
+	return r.Previous
+}
+
+// Replicate copies the versions missing in each target from the source, so
+// every target ends up with identical copies up to the source version. The
+// source is read by the balancer layer, while each target is published by its
+// own layer. Every target is replicated even when others fail, returning the
+// result of each target along with the first failure, or a PartialError when
+// other targets were changed.
+func (b *Balancer) Replicate(ctx context.Context, source *Version, targets []*Target) ([]*Replication, error) {
+	var (
+		g       errgroup.Group
+		mu      sync.Mutex
+		changed []string
+	)
+
+	results := make([]*Replication, len(targets))
+
+	for i, t := range targets {
+		g.Go(func() error {
+			previous, published, err := b.copyVersions(ctx, source, t.Layer, t.Account, t.Region)
+			if err == nil && len(published) > 0 {
+				err = b.hook(ctx, HookPostBump, &HookContext{
+					Layer:   t.Layer.Name,
+					Account: t.Account,
+					Region:  t.Region,
+					Source:  source,
+					Target:  published[len(published)-1],
+				})
+			}
+
+			if err != nil {
+				err = &AccountError{Account: t.Account, Err: &RegionError{Region: t.Region, Err: err}}
+			} else {
+				b.progress(Event{Account: t.Account, Region: t.Region, Stage: StageComplete, Version: source.Number})
+			}
+
+			results[i] = &Replication{
+				Account:   t.Account,
+				Region:    t.Region,
+				Previous:  previous,
+				Published: len(published),
+				Err:       err,
+			}
+
+			if len(published) > 0 {
+				results[i].Latest = published[len(published)-1].Number
+
+				mu.Lock()
+				changed = append(changed, t.Account+"/"+t.Region)
+				mu.Unlock()
+			}
+
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		if len(changed) > 0 {
+			return results, &PartialError{Changed: changed, Err: err}
+		}
+
+		return results, err
+	}
+
+	return results, nil
+}
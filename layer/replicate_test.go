@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+func TestBalancerReplicate(t *testing.T) {
+	source := &Version{Number: 10, Region: "us-east-1"}
+	hc := &http.Client{Transport: &mockResponder{}}
+
+	b := NewBalancer(aws.Config{}, "my-layer", WithClient(&mockSvc{}), WithHTTPClient(hc))
+
+	t.Run("replicate success", func(t *testing.T) {
+		// the target skipped version numbers, e.g. deleted versions.
+		skipped := driftSvc()
+		skipped.PublishLayerVersionFn = func() (*lambda.PublishLayerVersionOutput, error) {
+			return &lambda.PublishLayerVersionOutput{Version: 12}, nil
+		}
+
+		targets := []*Target{
+			{Account: "111111111111", Region: "eu-west-1", Layer: New(aws.Config{}, "my-layer", WithClient(driftSvc()))},
+			{Account: "222222222222", Region: "us-east-1", Layer: New(aws.Config{}, "my-layer", WithClient(skipped))},
+		}
+
+		results, err := b.Replicate(context.Background(), source, targets)
+		if err != nil {
+			t.Errorf("expected nil, got error %v", err)
+		}
+
+		if r := results[0]; r.Published != 0 || r.Version() != 10 {
+			t.Errorf("expected nothing published in eu-west-1, got '%d' (version %d)", r.Published, r.Version())
+		}
+
+		if r := results[1]; r.Published != 1 || r.Version() != 12 {
+			t.Errorf("expected one version published in us-east-1, got '%d' (version %d)", r.Published, r.Version())
+		}
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		failing := driftSvc()
+		failing.PublishLayerVersionFn = func() (*lambda.PublishLayerVersionOutput, error) {
+			return nil, errors.New("failure")
+		}
+
+		targets := []*Target{
+			{Account: "111111111111", Region: "us-east-1", Layer: New(aws.Config{}, "my-layer", WithClient(failing))},
+			{Account: "222222222222", Region: "us-east-1", Layer: New(aws.Config{}, "my-layer", WithClient(driftSvc()))},
+		}
+
+		results, err := b.Replicate(context.Background(), source, targets)
+
+		var partialErr *PartialError
+		if !errors.As(err, &partialErr) || partialErr.Changed[0] != "222222222222/us-east-1" {
+			t.Errorf("expected a partial error, got %v", err)
+		}
+
+		var accountErr *AccountError
+		if !errors.As(results[0].Err, &accountErr) || accountErr.Account != "111111111111" {
+			t.Errorf("expected an account error, got %v", results[0].Err)
+		}
+
+		if results[1].Err != nil {
+			t.Errorf("expected nil, got error %v", results[1].Err)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Wave is a set of regions bumped together during a rollout.
+type Wave struct {
+	Regions []string
+
+	// BakeTime is the time waited after the wave is bumped, before the
+	// health check and the next wave.
+	BakeTime time.Duration
+}
+
+// HealthCheck verifies the regions of the wave after its bake time, where the
+// wave number starts at 1. An error stops the rollout.
+type HealthCheck func(ctx context.Context, wave int, w Wave) error
+
+// Rollout defines the waves of a progressive bump.
+type Rollout struct {
+	Waves       []Wave
+	HealthCheck HealthCheck
+
+	// Atomic deletes the versions published by all waves when the rollout fails.
+	Atomic bool
+}
+
+// WaveError represents the failure of a rollout wave.
+type WaveError struct {
+	Wave int
+	Err  error
+}
+
+// Error returns the error message prefixed by the wave.
+func (e *WaveError) Error() string {
+	return fmt.Sprintf("wave %d: %v", e.Wave, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *WaveError) Unwrap() error {
+	return e.Err
+}
+
+// Rollout bumps the regions of the plan wave by wave, waiting the bake time
+// and running the health check between waves. The rollout stops at the first
+// failed wave, returning a WaveError, wrapped by a PartialError when previous
+// waves were bumped or by a RollbackError when the rollout is atomic.
+func (b *Balancer) Rollout(ctx context.Context, plan *Plan, r *Rollout) error {
+	published, err := b.rollout(ctx, plan, r)
+	if err != nil && r.Atomic {
+		return b.rollback(ctx, published, err)
+	}
+
+	return err
+}
+
+// rollout bumps the waves in order, returning the published versions.
+func (b *Balancer) rollout(ctx context.Context, plan *Plan, r *Rollout) ([]*Version, error) {
+	var published []*Version
+
+	for i, w := range r.Waves {
+		n := i + 1
+
+		b.progress(Event{Wave: n, Stage: StageWave, Version: plan.Source.Number})
+
+		versions, err := b.bump(ctx, plan.wave(w.Regions))
+		published = append(published, versions...)
+
+		if err == nil && n < len(r.Waves) {
+			err = b.bake(ctx, plan, n, w, r.HealthCheck)
+		}
+
+		if err == nil {
+			continue
+		}
+
+		var partial *PartialError
+		if errors.As(err, &partial) {
+			err = partial.Err
+		}
+
+		err = &WaveError{Wave: n, Err: err}
+
+		if len(published) > 0 {
+			return published, &PartialError{Changed: regionsOf(published), Err: err}
+		}
+
+		return published, err
+	}
+
+	return published, nil
+}
+
+// bake waits the bake time of the wave and runs the health check.
+func (b *Balancer) bake(ctx context.Context, plan *Plan, n int, w Wave, check HealthCheck) error {
+	if w.BakeTime > 0 {
+		b.progress(Event{Wave: n, Stage: StageBake, Version: plan.Source.Number})
+
+		timer := time.NewTimer(w.BakeTime)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if check == nil {
+		return nil
+	}
+
+	b.progress(Event{Wave: n, Stage: StageHealthCheck, Version: plan.Source.Number})
+
+	if err := check(ctx, n, w); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	return nil
+}
+
+// wave returns the plan of the regions.
+func (p *Plan) wave(regions []string) *Plan {
+	wave := &Plan{Source: p.Source}
+
+	for _, v := range p.Versions {
+		if slices.Contains(regions, v.Region) {
+			wave.Versions = append(wave.Versions, v)
+		}
+	}
+
+	return wave
+}
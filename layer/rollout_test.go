@@ -0,0 +1,169 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+func TestBalancerRollout(t *testing.T) {
+	plan := &Plan{
+		Source: &Version{Number: 11, Region: "eu-west-1"},
+		Versions: []*Version{
+			{Number: 10, Region: "us-east-1"},
+			{Number: 10, Region: "sa-east-1"},
+			{Number: 11, Region: "eu-west-1"},
+		},
+	}
+
+	waves := []Wave{
+		{Regions: []string{"us-east-1"}, BakeTime: time.Millisecond},
+		{Regions: []string{"sa-east-1", "eu-west-1"}},
+	}
+
+	hc := &http.Client{Transport: &mockResponder{}}
+
+	// events returns the balancer options recording the progress stages.
+	events := func(stages *[]string) Option {
+		var mu sync.Mutex
+
+		return WithProgress(func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if e.Stage != StageStart && e.Stage != StageDownload {
+				*stages = append(*stages, e.Region+e.Stage)
+			}
+		})
+	}
+
+	t.Run("rollout success", func(t *testing.T) {
+		var (
+			stages  []string
+			checked []int
+		)
+
+		b := NewBalancer(aws.Config{}, "my-layer", WithClient(&mockSvc{}), WithHTTPClient(hc), events(&stages))
+
+		err := b.Rollout(context.Background(), plan, &Rollout{
+			Waves: waves,
+			HealthCheck: func(_ context.Context, n int, w Wave) error {
+				checked = append(checked, n)
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected nil, got error %v", err)
+		}
+
+		expected := []string{
+			StageWave, "us-east-1" + StagePublish, "us-east-1" + StageComplete, StageBake, StageHealthCheck,
+			StageWave, "sa-east-1" + StagePublish, "sa-east-1" + StageComplete,
+			"eu-west-1" + StagePublish, "eu-west-1" + StageComplete,
+		}
+
+		// the regions of the last wave are bumped concurrently.
+		slices.Sort(stages[6:])
+		slices.Sort(expected[6:])
+
+		if !slices.Equal(stages, expected) {
+			t.Errorf("expected stages %v, got %v", expected, stages)
+		}
+
+		if !slices.Equal(checked, []int{1}) {
+			t.Errorf("expected the health check of wave '1' only, got %v", checked)
+		}
+	})
+
+	t.Run("health check failure", func(t *testing.T) {
+		var stages []string
+
+		b := NewBalancer(aws.Config{}, "my-layer", WithClient(&mockSvc{}), WithHTTPClient(hc), events(&stages))
+
+		err := b.Rollout(context.Background(), plan, &Rollout{
+			Waves: waves,
+			HealthCheck: func(context.Context, int, Wave) error {
+				return errors.New("unhealthy")
+			},
+		})
+
+		expected := "wave 1: health check failed: unhealthy (changed regions: us-east-1)"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Unexpected error: %v (expected %s)", err, expected)
+		}
+
+		var waveErr *WaveError
+		if !errors.As(err, &waveErr) || waveErr.Wave != 1 {
+			t.Errorf("expected a wave error, got %v", err)
+		}
+
+		if slices.Contains(stages, "sa-east-1"+StagePublish) {
+			t.Errorf("expected the rollout stopped, got stages %v", stages)
+		}
+	})
+
+	t.Run("canceled bake", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		b := NewBalancer(aws.Config{}, "my-layer", WithClient(&mockSvc{}), WithHTTPClient(hc))
+
+		err := b.Rollout(ctx, plan.wave([]string{"eu-west-1", "us-east-1"}), &Rollout{
+			Waves: []Wave{{Regions: []string{"eu-west-1"}, BakeTime: time.Hour}, {Regions: []string{"us-east-1"}}},
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected canceled error, got %v", err)
+		}
+	})
+
+	t.Run("atomic rollout", func(t *testing.T) {
+		var deleted []int64
+
+		b := NewBalancer(aws.Config{}, "my-layer",
+			WithClient(&mockSvc{
+				PublishLayerVersionFn: func() (*lambda.PublishLayerVersionOutput, error) {
+					return &lambda.PublishLayerVersionOutput{Version: 11}, nil
+				},
+				DeleteLayerVersionFn: func(in *lambda.DeleteLayerVersionInput) (*lambda.DeleteLayerVersionOutput, error) {
+					deleted = append(deleted, aws.ToInt64(in.VersionNumber))
+					return &lambda.DeleteLayerVersionOutput{}, nil
+				},
+			}),
+			WithHTTPClient(hc),
+		)
+
+		err := b.Rollout(context.Background(), plan, &Rollout{
+			Waves:  waves,
+			Atomic: true,
+			HealthCheck: func(context.Context, int, Wave) error {
+				return errors.New("unhealthy")
+			},
+		})
+
+		var rollbackErr *RollbackError
+		if !errors.As(err, &rollbackErr) || len(rollbackErr.RolledBack) != 1 {
+			t.Fatalf("expected a rollback error, got %v", err)
+		}
+
+		var waveErr *WaveError
+		if !errors.As(err, &waveErr) {
+			t.Errorf("expected a wave error, got %v", err)
+		}
+
+		if !slices.Equal(deleted, []int64{11}) {
+			t.Errorf("expected version '11' deleted, got %v", deleted)
+		}
+	})
+}
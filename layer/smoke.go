@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package layer
+
+import (
+	"context"
+	"fmt"
+)
+
+// SmokeTest verifies that the version published in the region works, e.g. by
+// invoking a function using it. An error fails the bump of the region.
+type SmokeTest func(ctx context.Context, v *Version) error
+
+// SmokeRestore reverts the changes made by the smoke test of the version, e.g.
+// the function updated to use it, run before the version is rolled back.
+type SmokeRestore func(ctx context.Context, v *Version) error
+
+// smoke runs the smoke test of the published version, when one is set.
+func (b *Balancer) smoke(ctx context.Context, v *Version) error {
+	if b.smokeTest == nil {
+		return nil
+	}
+
+	b.progress(Event{Region: v.Region, Stage: StageSmokeTest, Version: v.Number})
+
+	if err := b.smokeTest(ctx, v); err != nil {
+		return fmt.Errorf("smoke test of version %d failed: %w", v.Number, err)
+	}
+
+	return nil
+}
+
+// restoreSmoke reverts the smoke test of the rolled back version, when a
+// restore is set.
+func (b *Balancer) restoreSmoke(ctx context.Context, v *Version) error {
+	if b.smokeRestore == nil {
+		return nil
+	}
+
+	if err := b.smokeRestore(ctx, v); err != nil {
+		return fmt.Errorf("smoke test restore of version %d failed: %w", v.Number, err)
+	}
+
+	return nil
+}
@@ -13,5 +13,12 @@ import (
 )
 
 func main() {
-	cmd.Execute(context.Background(), os.Args)
+	ctx := context.Background()
+
+	if cmd.IsLambda() {
+		cmd.StartLambda(ctx)
+		return
+	}
+
+	cmd.Execute(ctx, os.Args)
 }